@@ -0,0 +1,125 @@
+package billing
+
+import (
+	"fmt"
+	"strings"
+	"subscription-tracker/models"
+)
+
+// BudgetNearRatio is the share of a budget from which spending counts as near
+// the limit
+const BudgetNearRatio = 0.9
+
+// BudgetStatus tells how spending compares to a budget
+type BudgetStatus string
+
+const (
+	BudgetWithin BudgetStatus = "within"
+	BudgetNear   BudgetStatus = "near"
+	BudgetOver   BudgetStatus = "over"
+)
+
+// BudgetCheck compares the month-normalized spending of a category with its
+// budget, both in the base currency
+type BudgetCheck struct {
+	Budget models.Budget
+	Spent  models.Money
+	Limit  models.Money
+	Status BudgetStatus
+}
+
+// String describes the check, e.g. "Streaming: $95.00 of $100.00 (near)"
+func (c BudgetCheck) String() string {
+	return fmt.Sprintf("%s: %s of %s (%s)", c.Budget.Label(), c.Spent, c.Limit, c.Status)
+}
+
+// CheckBudgets compares the average monthly cost of the active subscriptions
+// with every budget, in the order the budgets are defined. Budgets or
+// subscriptions whose currency has no rate are left out and reported in the
+// returned error.
+func CheckBudgets(subs []*models.Subscription, budgets []models.Budget, rates models.ExchangeRates) ([]BudgetCheck, error) {
+	var checks []BudgetCheck
+	var missing []string
+
+	for _, budget := range budgets {
+		limit, err := rates.Convert(budget.Monthly)
+		if err != nil {
+			missing = append(missing, "budget "+budget.Label())
+			continue
+		}
+
+		var covered []*models.Subscription
+		for _, sub := range subs {
+			if budget.Covers(sub) {
+				covered = append(covered, sub)
+			}
+		}
+		spent, err := TotalPer(covered, rates, models.PeriodMonth)
+		if err != nil {
+			missing = append(missing, err.Error())
+		}
+
+		checks = append(checks, BudgetCheck{
+			Budget: budget,
+			Spent:  spent,
+			Limit:  limit,
+			Status: budgetStatus(spent, limit),
+		})
+	}
+
+	if len(missing) > 0 {
+		return checks, fmt.Errorf("budgets are incomplete: %s", strings.Join(missing, "; "))
+	}
+	return checks, nil
+}
+
+func budgetStatus(spent, limit models.Money) BudgetStatus {
+	switch {
+	case spent.Amount > limit.Amount:
+		return BudgetOver
+	case float64(spent.Amount) >= float64(limit.Amount)*BudgetNearRatio:
+		return BudgetNear
+	default:
+		return BudgetWithin
+	}
+}
+
+// FlaggedBudgets returns the checks that are near or over their budget
+func FlaggedBudgets(checks []BudgetCheck) []BudgetCheck {
+	var flagged []BudgetCheck
+	for _, check := range checks {
+		if check.Status != BudgetWithin {
+			flagged = append(flagged, check)
+		}
+	}
+	return flagged
+}
+
+// BudgetImpact returns the budgets that would be near or over their limit if
+// the candidate were saved, and that it pushes further up. The candidate
+// replaces the subscription with the same ID, or is added when it is new.
+func BudgetImpact(subs []*models.Subscription, candidate *models.Subscription, budgets []models.Budget, rates models.ExchangeRates) []BudgetCheck {
+	before, _ := CheckBudgets(subs, budgets, rates)
+
+	updated := make([]*models.Subscription, 0, len(subs)+1)
+	for _, sub := range subs {
+		if sub.ID() != candidate.ID() {
+			updated = append(updated, sub)
+		}
+	}
+	updated = append(updated, candidate)
+	after, _ := CheckBudgets(updated, budgets, rates)
+
+	var impact []BudgetCheck
+	for _, check := range after {
+		if check.Status == BudgetWithin {
+			continue
+		}
+		for _, previous := range before {
+			if previous.Budget.Category == check.Budget.Category && check.Spent.Amount > previous.Spent.Amount {
+				impact = append(impact, check)
+			}
+		}
+	}
+	return impact
+}
@@ -0,0 +1,136 @@
+package billing
+
+import (
+	"subscription-tracker/models"
+	"testing"
+)
+
+// newMonthlySubscription creates a monthly subscription in the category
+func newMonthlySubscription(t *testing.T, category string, cost models.Money) *models.Subscription {
+	t.Helper()
+	sub, err := models.NewSubscription(category+" subscription", cost, models.FrequencyMonthly, today().AddDate(0, 0, 10), models.OpenEnded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sub.SetCategory(category); err != nil {
+		t.Fatal(err)
+	}
+	return sub
+}
+
+func TestBudgetStatus(t *testing.T) {
+	budgets := []models.Budget{{Category: "Streaming", Monthly: usd(10000)}}
+	rates := models.ExchangeRates{Base: "USD"}
+	tests := []struct {
+		spent int64
+		want  BudgetStatus
+	}{
+		{0, BudgetWithin},
+		{8999, BudgetWithin},
+		{9000, BudgetNear},
+		{9999, BudgetNear},
+		{10000, BudgetNear},
+		{10001, BudgetOver},
+	}
+	for _, tt := range tests {
+		var subs []*models.Subscription
+		if tt.spent > 0 {
+			subs = append(subs, newMonthlySubscription(t, "Streaming", usd(tt.spent)))
+		}
+		// Other categories do not count
+		subs = append(subs, newMonthlySubscription(t, "Music", usd(5000)))
+
+		checks, err := CheckBudgets(subs, budgets, rates)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(checks) != 1 || checks[0].Spent != usd(tt.spent) || checks[0].Status != tt.want {
+			t.Errorf("spending %s: checks = %v, want %s", usd(tt.spent), checks, tt.want)
+		}
+		if flagged := FlaggedBudgets(checks); (len(flagged) > 0) != (tt.want != BudgetWithin) {
+			t.Errorf("spending %s: flagged = %v", usd(tt.spent), flagged)
+		}
+	}
+}
+
+func TestCheckBudgetsConvertsCurrencies(t *testing.T) {
+	rates := models.ExchangeRates{Base: "USD", Rates: map[string]float64{"EUR": 2}}
+	subs := []*models.Subscription{
+		newMonthlySubscription(t, "Streaming", usd(3000)),
+		newMonthlySubscription(t, "streaming", models.Money{Amount: 1000, Currency: "EUR"}),
+		newMonthlySubscription(t, "Music", usd(1000)),
+	}
+	// A yearly subscription counts with its monthly average
+	yearly, err := models.NewSubscription("Cloud", usd(12000), models.FrequencyYearly, today().AddDate(0, 0, 10), models.OpenEnded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	subs = append(subs, yearly)
+
+	budgets := []models.Budget{
+		{Category: "Streaming", Monthly: models.Money{Amount: 2500, Currency: "EUR"}},
+		{Category: models.OverallBudget, Monthly: usd(6500)},
+	}
+	checks, err := CheckBudgets(subs, budgets, rates)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []BudgetCheck{
+		{Budget: budgets[0], Spent: usd(5000), Limit: usd(5000), Status: BudgetNear},
+		{Budget: budgets[1], Spent: usd(7000), Limit: usd(6500), Status: BudgetOver},
+	}
+	if len(checks) != len(want) {
+		t.Fatalf("checks = %v, want %v", checks, want)
+	}
+	for i := range want {
+		if checks[i] != want[i] {
+			t.Errorf("check %d = %v, want %v", i+1, checks[i], want[i])
+		}
+	}
+
+	// Budgets and subscriptions without a rate are reported
+	budgets = append(budgets, models.Budget{Category: "Music", Monthly: models.Money{Amount: 1000, Currency: "GBP"}})
+	checks, err = CheckBudgets(subs, budgets, models.ExchangeRates{Base: "USD"})
+	if err == nil {
+		t.Error("missing rates were not reported")
+	}
+	if len(checks) != 1 || checks[0].Spent != usd(5000) {
+		t.Errorf("checks = %v, want only the overall budget without the EUR subscription", checks)
+	}
+}
+
+func TestBudgetImpact(t *testing.T) {
+	rates := models.ExchangeRates{Base: "USD"}
+	budgets := []models.Budget{
+		{Category: "Streaming", Monthly: usd(2000)},
+		{Category: models.OverallBudget, Monthly: usd(10000)},
+	}
+	existing := newMonthlySubscription(t, "Streaming", usd(1500))
+	subs := []*models.Subscription{existing, newMonthlySubscription(t, "Music", usd(1000))}
+
+	// A new subscription pushing the category over its budget
+	impact := BudgetImpact(subs, newMonthlySubscription(t, "Streaming", usd(1000)), budgets, rates)
+	if len(impact) != 1 || impact[0].Budget.Category != "Streaming" || impact[0].Status != BudgetOver {
+		t.Errorf("impact = %v, want Streaming over", impact)
+	}
+
+	// An edit replaces the subscription instead of adding to it
+	edited := existing.Clone()
+	if err := edited.SetCost(usd(1900)); err != nil {
+		t.Fatal(err)
+	}
+	impact = BudgetImpact(subs, edited, budgets, rates)
+	if len(impact) != 1 || impact[0].Spent != usd(1900) || impact[0].Status != BudgetNear {
+		t.Errorf("impact = %v, want Streaming near at $19.00", impact)
+	}
+
+	// Lowering the cost of a subscription in a flagged budget is no impact
+	subs[0] = edited
+	cheaper := edited.Clone()
+	if err := cheaper.SetCost(usd(1850)); err != nil {
+		t.Fatal(err)
+	}
+	if impact := BudgetImpact(subs, cheaper, budgets, rates); len(impact) != 0 {
+		t.Errorf("impact = %v, want none", impact)
+	}
+}
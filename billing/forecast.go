@@ -0,0 +1,137 @@
+package billing
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"subscription-tracker/models"
+	"time"
+)
+
+// ForecastEvent is a single expected payment
+type ForecastEvent struct {
+	SubscriptionID string
+	Name           string
+	Date           time.Time
+	Amount         models.Money
+	// Converted is the amount in the base currency, or zero when no rate is
+	// available for the amount's currency
+	Converted models.Money
+}
+
+// PeriodTotal adds up the expected payments of one day or month
+type PeriodTotal struct {
+	Start  time.Time
+	Total  models.Money
+	Events []ForecastEvent
+}
+
+// Forecast holds the payments expected over a horizon, ordered by date
+type Forecast struct {
+	From   time.Time
+	Until  time.Time
+	Base   string
+	Events []ForecastEvent
+}
+
+// BuildForecast walks every subscription's schedule over the given number of
+// months from now, moving payments off weekends and holidays in the settings'
+// calendar. Amounts are converted into the base currency; subscriptions whose
+// currency has no rate are kept with a zero converted amount and reported in
+// the returned error.
+func BuildForecast(subs []*models.Subscription, settings models.Settings, now time.Time, months int) (Forecast, error) {
+	rates := settings.ExchangeRates
+	calendar := settings.Calendar()
+	// Months and days are grouped in the configured time zone
+	now = now.In(settings.Location())
+	forecast := Forecast{
+		From:  now,
+		Until: now.AddDate(0, months, 0),
+		Base:  rates.Base,
+	}
+
+	var missing []string
+	for _, sub := range subs {
+		payments := sub.Forecast(forecast.From, forecast.Until, calendar)
+		for i, payment := range payments {
+			converted, err := rates.Convert(payment.Amount)
+			if err != nil {
+				converted = models.Money{Currency: rates.Base}
+				if i == 0 {
+					missing = append(missing, sub.Name())
+				}
+			}
+			forecast.Events = append(forecast.Events, ForecastEvent{
+				SubscriptionID: sub.ID(),
+				Name:           sub.Name(),
+				Date:           payment.Date,
+				Amount:         payment.Amount,
+				Converted:      converted,
+			})
+		}
+	}
+
+	sort.SliceStable(forecast.Events, func(i, j int) bool {
+		return forecast.Events[i].Date.Before(forecast.Events[j].Date)
+	})
+
+	if len(missing) > 0 {
+		return forecast, fmt.Errorf("missing exchange rates for: %s", strings.Join(missing, ", "))
+	}
+	return forecast, nil
+}
+
+// Total returns the sum of all expected payments in the base currency
+func (f Forecast) Total() models.Money {
+	total := models.Money{Currency: f.Base}
+	for _, event := range f.Events {
+		total.Amount += event.Converted.Amount
+	}
+	return total
+}
+
+// ByDay totals the expected payments per calendar day, leaving out days
+// without payments
+func (f Forecast) ByDay() []PeriodTotal {
+	return f.groupBy(func(date time.Time) time.Time {
+		year, month, day := date.Date()
+		return time.Date(year, month, day, 0, 0, 0, 0, date.Location())
+	}, false)
+}
+
+// ByMonth totals the expected payments per calendar month. Every month of
+// the horizon is listed, including months without payments.
+func (f Forecast) ByMonth() []PeriodTotal {
+	return f.groupBy(monthStart, true)
+}
+
+func monthStart(date time.Time) time.Time {
+	year, month, _ := date.Date()
+	return time.Date(year, month, 1, 0, 0, 0, 0, date.Location())
+}
+
+func (f Forecast) groupBy(start func(time.Time) time.Time, everyMonth bool) []PeriodTotal {
+	var totals []PeriodTotal
+	if everyMonth {
+		for month := monthStart(f.From); month.Before(f.Until); month = month.AddDate(0, 1, 0) {
+			totals = append(totals, PeriodTotal{Start: month, Total: models.Money{Currency: f.Base}})
+		}
+	}
+
+	for _, event := range f.Events {
+		// Group in the time zone of the horizon so events stored in other
+		// zones land in the same periods
+		periodStart := start(event.Date.In(f.From.Location()))
+		index := sort.Search(len(totals), func(i int) bool {
+			return !totals[i].Start.Before(periodStart)
+		})
+		if index == len(totals) || !totals[index].Start.Equal(periodStart) {
+			totals = append(totals, PeriodTotal{})
+			copy(totals[index+1:], totals[index:])
+			totals[index] = PeriodTotal{Start: periodStart, Total: models.Money{Currency: f.Base}}
+		}
+		totals[index].Total.Amount += event.Converted.Amount
+		totals[index].Events = append(totals[index].Events, event)
+	}
+	return totals
+}
@@ -0,0 +1,146 @@
+package billing
+
+import (
+	"subscription-tracker/models"
+	"testing"
+	"time"
+)
+
+func TestBuildForecast(t *testing.T) {
+	// Every subscription costs $10 a month and is first due tomorrow
+	tests := []struct {
+		name          string
+		totalPayments int
+		months        int
+		configure     func(sub *models.Subscription) error
+		want          []int64
+	}{
+		{"open-ended", models.OpenEnded, 3, nil, []int64{1000, 1000, 1000}},
+		{"payments left", 2, 6, nil, []int64{1000, 1000}},
+		{"empty horizon", models.OpenEnded, 0, nil, nil},
+		{"recurrence rule", models.OpenEnded, 2, func(sub *models.Subscription) error {
+			// Every other week from tomorrow, within at least 59 days
+			return sub.SetRecurrence("FREQ=WEEKLY;INTERVAL=2", sub.NextPaymentDate())
+		}, []int64{1000, 1000, 1000, 1000, 1000}},
+		{"recurrence rule with count", models.OpenEnded, 6, func(sub *models.Subscription) error {
+			return sub.SetRecurrence("FREQ=MONTHLY;COUNT=2", sub.NextPaymentDate())
+		}, []int64{1000, 1000}},
+		{"promotion", models.OpenEnded, 4, func(sub *models.Subscription) error {
+			return sub.SetPromotion(models.Promotion{Price: usd(500), Payments: 2})
+		}, []int64{500, 500, 1000, 1000}},
+		{"scheduled price", models.OpenEnded, 3, func(sub *models.Subscription) error {
+			// Effective a day after the first payment, so from the second
+			return sub.SchedulePriceChange(usd(1200), sub.NextPaymentDate().AddDate(0, 0, 1))
+		}, []int64{1000, 1200, 1200}},
+		{"promotion before scheduled price", models.OpenEnded, 3, func(sub *models.Subscription) error {
+			if err := sub.SchedulePriceChange(usd(1200), sub.NextPaymentDate().AddDate(0, 0, 1)); err != nil {
+				return err
+			}
+			return sub.SetPromotion(models.Promotion{Price: usd(500), Payments: 1})
+		}, []int64{500, 1200, 1200}},
+		{"cancelled", models.OpenEnded, 3, func(sub *models.Subscription) error {
+			return sub.Cancel(time.Time{})
+		}, nil},
+		{"paused", models.OpenEnded, 3, func(sub *models.Subscription) error {
+			return sub.Pause(today().AddDate(1, 0, 0))
+		}, nil},
+	}
+
+	settings := models.DefaultSettings()
+	settings.TimeZone = "UTC"
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub, err := models.NewSubscription("Test", usd(1000), models.FrequencyMonthly, today().AddDate(0, 0, 1), tt.totalPayments)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.configure != nil {
+				if err := tt.configure(sub); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			forecast, err := BuildForecast([]*models.Subscription{sub}, settings, today(), tt.months)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []int64
+			for i, event := range forecast.Events {
+				got = append(got, event.Amount.Amount)
+				if event.Date.Before(forecast.From) || !event.Date.Before(forecast.Until) {
+					t.Errorf("payment on %s is outside the horizon", event.Date.Format("2006-01-02"))
+				}
+				if i > 0 && event.Date.Before(forecast.Events[i-1].Date) {
+					t.Errorf("payment on %s is out of order", event.Date.Format("2006-01-02"))
+				}
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("amounts = %v, want %v", got, tt.want)
+			}
+			var total int64
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("amounts = %v, want %v", got, tt.want)
+					break
+				}
+				total += got[i]
+			}
+			if forecast.Total() != usd(total) {
+				t.Errorf("Total = %s, want %s", forecast.Total(), usd(total))
+			}
+		})
+	}
+}
+
+func TestForecastByMonth(t *testing.T) {
+	settings := models.DefaultSettings()
+	settings.TimeZone = "UTC"
+	settings.ExchangeRates.Rates["EUR"] = 1.5
+	newSub := func(cost models.Money, days int) *models.Subscription {
+		t.Helper()
+		sub, err := models.NewSubscription("Test", cost, models.FrequencyMonthly, today().AddDate(0, 0, days), models.OpenEnded)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return sub
+	}
+	subs := []*models.Subscription{newSub(usd(1000), 1), newSub(models.Money{Amount: 1000, Currency: "EUR"}, 2)}
+
+	forecast, err := BuildForecast(subs, settings, today(), 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if forecast.Total() != usd(3*1000+3*1500) {
+		t.Errorf("Total = %s, want $75.00", forecast.Total())
+	}
+
+	// A horizon starting mid-month ends in a fourth, partial month
+	want := 4
+	if today().Day() == 1 {
+		want = 3
+	}
+	months := forecast.ByMonth()
+	if len(months) != want {
+		t.Fatalf("%d months, want %d", len(months), want)
+	}
+	var total int64
+	for i, month := range months {
+		if month.Start.Day() != 1 || (i > 0 && !month.Start.Equal(months[i-1].Start.AddDate(0, 1, 0))) {
+			t.Errorf("month %d starts on %s", i+1, month.Start.Format("2006-01-02"))
+		}
+		total += month.Total.Amount
+	}
+	if total != forecast.Total().Amount {
+		t.Errorf("monthly totals add up to %d, want %d", total, forecast.Total().Amount)
+	}
+
+	// Subscriptions without a rate are forecast but not converted
+	delete(settings.ExchangeRates.Rates, "EUR")
+	forecast, err = BuildForecast(subs, settings, today(), 3)
+	if err == nil {
+		t.Error("missing rate was not reported")
+	}
+	if len(forecast.Events) != 6 || forecast.Total() != usd(3000) {
+		t.Errorf("%d events totalling %s, want 6 totalling $30.00", len(forecast.Events), forecast.Total())
+	}
+}
@@ -0,0 +1,44 @@
+package billing
+
+import (
+	"fmt"
+	"subscription-tracker/models"
+	"subscription-tracker/storage"
+)
+
+// ReassignPaymentMethod moves every subscription paid with one payment method
+// to another and returns how many were moved. An empty target removes the
+// payment method from those subscriptions. Subscriptions updated before a
+// failure keep their new payment method.
+func ReassignPaymentMethod(store storage.Storage, fromID, toID string) (int, error) {
+	if fromID == toID {
+		return 0, nil
+	}
+
+	moved := 0
+	for _, sub := range store.GetSubscriptions() {
+		if sub.PaymentMethodID() != fromID {
+			continue
+		}
+		updatedSub := sub.Clone()
+		if err := updatedSub.SetPaymentMethodID(toID); err != nil {
+			return moved, err
+		}
+		if err := store.UpdateSubscription(updatedSub.ID(), updatedSub); err != nil {
+			return moved, fmt.Errorf("failed to reassign '%s': %v", sub.Name(), err)
+		}
+		moved++
+	}
+	return moved, nil
+}
+
+// CountByPaymentMethod returns how many subscriptions use each payment method
+func CountByPaymentMethod(subs []*models.Subscription) map[string]int {
+	counts := make(map[string]int)
+	for _, sub := range subs {
+		if id := sub.PaymentMethodID(); id != "" {
+			counts[id]++
+		}
+	}
+	return counts
+}
@@ -0,0 +1,175 @@
+package billing
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"subscription-tracker/models"
+	"subscription-tracker/storage"
+	"sync"
+	"time"
+)
+
+// Advance describes the payments processed for a single subscription
+// during a catch-up run
+type Advance struct {
+	Name            string
+	Payments        int
+	NextPaymentDate time.Time
+	Completed       bool
+	ConvertedTrial  bool
+	Resumed         bool
+	PriceChanges    []models.PriceChange
+	// PromotionEnded is set when the promotional price ran out, with the
+	// regular price charged from now on
+	PromotionEnded bool
+	RegularPrice   models.Money
+}
+
+// Report summarizes a catch-up run
+type Report struct {
+	Advances []Advance
+	Errors   []error
+}
+
+// TotalPayments returns the number of payments processed across all subscriptions
+func (r Report) TotalPayments() int {
+	total := 0
+	for _, advance := range r.Advances {
+		total += advance.Payments
+	}
+	return total
+}
+
+// Summary returns a human readable description of the run
+func (r Report) Summary() string {
+	if len(r.Advances) == 0 && len(r.Errors) == 0 {
+		return "No payments were due"
+	}
+
+	var lines []string
+	if total := r.TotalPayments(); total > 0 {
+		lines = append(lines, fmt.Sprintf("Processed %d payment(s) across %d subscription(s):",
+			total, len(r.Advances)))
+	}
+	for _, advance := range r.Advances {
+		if advance.Resumed {
+			lines = append(lines, fmt.Sprintf("%s: resumed after pause", advance.Name))
+		}
+		if advance.ConvertedTrial {
+			lines = append(lines, fmt.Sprintf("%s: trial ended, converted to paid", advance.Name))
+		}
+		if advance.PromotionEnded {
+			lines = append(lines, fmt.Sprintf("%s: promotional price ended, now %s", advance.Name, advance.RegularPrice))
+		}
+		for _, change := range advance.PriceChanges {
+			lines = append(lines, fmt.Sprintf("%s: price changed from %s to %s on %s",
+				advance.Name, change.Previous, change.Cost, change.EffectiveDate.Format("2006-01-02")))
+		}
+		if advance.Payments == 0 {
+			continue
+		}
+		if advance.Completed {
+			lines = append(lines, fmt.Sprintf("%s: %d payment(s), now completed", advance.Name, advance.Payments))
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("%s: %d payment(s), next on %s",
+			advance.Name, advance.Payments, advance.NextPaymentDate.Format("2006-01-02")))
+	}
+	for _, err := range r.Errors {
+		lines = append(lines, fmt.Sprintf("Error: %v", err))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Processor advances subscriptions through every payment cycle that has
+// elapsed and persists the result
+type Processor struct {
+	storage  storage.Storage
+	settings storage.SettingsStorage
+	mu       sync.Mutex
+}
+
+func NewProcessor(storage storage.Storage, settings storage.SettingsStorage) *Processor {
+	return &Processor{storage: storage, settings: settings}
+}
+
+// CatchUp processes all payments that are due at the given time, resumes
+// paused subscriptions whose resume date has come and applies scheduled price
+// changes that have taken effect. Paused and cancelled subscriptions are not
+// charged. Payments are made on the business day their subscription's roll
+// convention moves them to in the holiday calendar. Subscriptions that have
+// missed several cycles are advanced through all of them in one run.
+func (p *Processor) CatchUp(now time.Time) Report {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	calendar := p.settings.GetSettings().Calendar()
+	var report Report
+	for _, sub := range p.storage.GetSubscriptions() {
+		advance, err := p.catchUpSubscription(sub, calendar, now)
+		if advance.Payments > 0 || advance.Resumed || advance.ConvertedTrial || len(advance.PriceChanges) > 0 {
+			report.Advances = append(report.Advances, advance)
+		}
+		if err != nil {
+			report.Errors = append(report.Errors, err)
+		}
+	}
+	return report
+}
+
+func (p *Processor) catchUpSubscription(stored *models.Subscription, calendar *models.HolidayCalendar, now time.Time) (Advance, error) {
+	// Work on a copy so the stored subscription only changes once the result
+	// is saved
+	sub := stored.Clone()
+	advance := Advance{Name: sub.Name()}
+	appliedPrices := len(sub.PriceHistory())
+	_, hadPromotion := sub.Promotion()
+
+	// Paused subscriptions whose resume date has come are billed again from
+	// their next payment after now
+	resumed, processErr := sub.ResumeIfDue(now)
+	if processErr != nil {
+		processErr = fmt.Errorf("failed to resume '%s': %v", sub.Name(), processErr)
+	}
+	advance.Resumed = resumed
+
+	// Trials end on their end date, even when the first charge comes later
+	advance.ConvertedTrial = sub.EndTrialIfDue(now)
+
+	inTrial := sub.InTrial()
+	for processErr == nil && sub.IsDue(now, calendar) {
+		if err := sub.ProcessPayment(calendar); err != nil {
+			processErr = fmt.Errorf("failed to process payment for '%s': %v", sub.Name(), err)
+			break
+		}
+		advance.Payments++
+	}
+
+	// Prices change on their effective date even when no payment is due
+	sub.ApplyPriceChanges(now)
+	advance.PriceChanges = sub.PriceHistory()[appliedPrices:]
+
+	if advance.Payments == 0 && !advance.Resumed && !advance.ConvertedTrial && len(advance.PriceChanges) == 0 {
+		return advance, processErr
+	}
+
+	advance.NextPaymentDate = sub.NextPaymentDate()
+	advance.Completed = sub.HasEnded()
+	advance.ConvertedTrial = advance.ConvertedTrial || (inTrial && !sub.InTrial())
+	if _, ok := sub.Promotion(); hadPromotion && !ok && !advance.Completed {
+		advance.PromotionEnded = true
+		advance.RegularPrice = sub.NextPaymentAmount()
+	}
+
+	err := p.storage.UpdateSubscription(sub.ID(), sub)
+	if errors.Is(err, storage.ErrStale) {
+		// The subscription was edited while being processed; the next run
+		// starts again from the edited version
+		return Advance{Name: sub.Name()}, nil
+	}
+	if err != nil {
+		return advance, fmt.Errorf("failed to persist payments for '%s': %v", sub.Name(), err)
+	}
+	return advance, processErr
+}
@@ -0,0 +1,204 @@
+package billing
+
+import (
+	"path/filepath"
+	"subscription-tracker/models"
+	"subscription-tracker/storage"
+	"testing"
+	"time"
+)
+
+func usd(amount int64) models.Money {
+	return models.Money{Amount: amount, Currency: "USD"}
+}
+
+// today is midnight UTC, the time zone the test storage reads dates in
+func today() time.Time {
+	return models.Today(time.Now(), time.UTC)
+}
+
+// newTestProcessor returns a processor over an empty temp-dir storage with
+// default settings
+func newTestProcessor(t *testing.T) (*Processor, *storage.JSONStorage) {
+	t.Helper()
+	dir := t.TempDir()
+	store, err := storage.NewJSONStorage(filepath.Join(dir, "subscriptions.json"), time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	settings, err := storage.NewJSONSettingsStorage(filepath.Join(dir, "settings.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewProcessor(store, settings), store
+}
+
+// addSubscription stores a monthly subscription of $10 due days from today
+func addSubscription(t *testing.T, store storage.Storage, name string, days int, totalPayments int, configure func(*models.Subscription) error) *models.Subscription {
+	t.Helper()
+	sub, err := models.NewSubscription(name, usd(1000), models.FrequencyMonthly, today().AddDate(0, 0, days), totalPayments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if configure != nil {
+		if err := configure(sub); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.AddSubscription(sub); err != nil {
+		t.Fatal(err)
+	}
+	return sub
+}
+
+func TestCatchUpEndsTrialBeforeFirstCharge(t *testing.T) {
+	processor, store := newTestProcessor(t)
+	// The trial ends on day 7 and the first charge follows on day 30
+	sub := addSubscription(t, store, "Trial", 30, models.OpenEnded, func(sub *models.Subscription) error {
+		return sub.SetTrial(today().AddDate(0, 0, 7))
+	})
+
+	if report := processor.CatchUp(today().AddDate(0, 0, 6)); len(report.Advances) != 0 {
+		t.Errorf("trial converted before its end: %+v", report.Advances)
+	}
+
+	report := processor.CatchUp(today().AddDate(0, 0, 10))
+	if len(report.Errors) > 0 {
+		t.Fatal(report.Errors)
+	}
+	if len(report.Advances) != 1 || !report.Advances[0].ConvertedTrial || report.Advances[0].Payments != 0 {
+		t.Fatalf("advances = %+v, want a converted trial without payments", report.Advances)
+	}
+	stored, err := store.GetSubscription(sub.ID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.InTrial() {
+		t.Error("converted trial was not saved")
+	}
+	if !stored.NextPaymentDate().Equal(today().AddDate(0, 0, 30)) || len(stored.Payments()) != 0 {
+		t.Errorf("conversion charged early: next payment %s, %d payment(s)",
+			stored.NextPaymentDate().Format("2006-01-02"), len(stored.Payments()))
+	}
+}
+
+func TestCatchUp(t *testing.T) {
+	processor, store := newTestProcessor(t)
+	open := addSubscription(t, store, "Open", 1, models.OpenEnded, nil)
+	fixed := addSubscription(t, store, "Fixed", 1, 2, nil)
+	paused := addSubscription(t, store, "Paused", 1, models.OpenEnded, func(sub *models.Subscription) error {
+		return sub.Pause(time.Time{})
+	})
+	cancelled := addSubscription(t, store, "Cancelled", 1, models.OpenEnded, func(sub *models.Subscription) error {
+		return sub.Cancel(time.Time{})
+	})
+	future := addSubscription(t, store, "Future", 200, models.OpenEnded, nil)
+
+	// Four monthly cycles have passed for the subscriptions due tomorrow
+	now := today().AddDate(0, 3, 5)
+	report := processor.CatchUp(now)
+	if len(report.Errors) > 0 {
+		t.Fatal(report.Errors)
+	}
+
+	want := map[string]int{open.Name(): 4, fixed.Name(): 2}
+	if len(report.Advances) != len(want) {
+		t.Errorf("advances = %+v, want %d", report.Advances, len(want))
+	}
+	for _, advance := range report.Advances {
+		if advance.Payments != want[advance.Name] {
+			t.Errorf("%s: %d payment(s), want %d", advance.Name, advance.Payments, want[advance.Name])
+		}
+		if advance.Completed != (advance.Name == fixed.Name()) {
+			t.Errorf("%s: completed = %t", advance.Name, advance.Completed)
+		}
+	}
+	if report.TotalPayments() != 6 {
+		t.Errorf("TotalPayments = %d, want 6", report.TotalPayments())
+	}
+
+	stored, err := store.GetSubscription(open.ID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next := stored.NextPaymentDate(); !next.After(now) || next.After(now.AddDate(0, 1, 0)) {
+		t.Errorf("next payment on %s, want the first one after %s", next.Format("2006-01-02"), now.Format("2006-01-02"))
+	}
+	payments := stored.Payments()
+	for i, payment := range payments {
+		if i > 0 && !payment.Date.After(payments[i-1].Date) {
+			t.Errorf("payment %d on %s does not follow the one before", i+1, payment.Date.Format("2006-01-02"))
+		}
+	}
+	for _, sub := range []*models.Subscription{paused, cancelled, future} {
+		stored, err := store.GetSubscription(sub.ID())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(stored.Payments()) != 0 {
+			t.Errorf("%s was charged", sub.Name())
+		}
+	}
+
+	// A second run has nothing left to do
+	if report := processor.CatchUp(now); len(report.Advances) != 0 || len(report.Errors) != 0 {
+		t.Errorf("second run = %+v", report)
+	}
+}
+
+func TestCatchUpSavesPayments(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "subscriptions.json")
+	store, err := storage.NewJSONStorage(path, time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	settings, err := storage.NewJSONSettingsStorage(filepath.Join(dir, "settings.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sub := addSubscription(t, store, "Music", 1, models.OpenEnded, nil)
+
+	if report := NewProcessor(store, settings).CatchUp(today().AddDate(0, 1, 5)); report.TotalPayments() != 2 {
+		t.Fatalf("report = %+v, want 2 payments", report)
+	}
+
+	reloaded, err := storage.NewJSONStorage(path, time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored, err := reloaded.GetSubscription(sub.ID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := sub.NextPaymentDate()
+	third := models.FrequencyMonthly.NextAnchored(models.FrequencyMonthly.NextAnchored(first, first.Day()), first.Day())
+	if len(stored.Payments()) != 2 || !stored.NextPaymentDate().Equal(third) {
+		t.Errorf("saved %d payment(s) with the next on %s", len(stored.Payments()), stored.NextPaymentDate().Format("2006-01-02"))
+	}
+}
+
+func TestCatchUpSkipsSubscriptionsEditedMeanwhile(t *testing.T) {
+	processor, store := newTestProcessor(t)
+	sub := addSubscription(t, store, "Music", 1, models.OpenEnded, nil)
+	// The subscription is edited while its payments are being processed
+	racing := &racingStorage{Storage: store, raced: map[string]bool{}}
+	processor.storage = racing
+
+	now := today().AddDate(0, 0, 5)
+	if report := processor.CatchUp(now); len(report.Advances) != 0 || len(report.Errors) != 0 {
+		t.Errorf("report = %+v, want the subscription left for the next run", report)
+	}
+	stored, err := store.GetSubscription(sub.ID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stored.Payments()) != 0 {
+		t.Error("payments were saved over the edit")
+	}
+
+	// The next run starts from the edited subscription
+	if report := processor.CatchUp(now); report.TotalPayments() != 1 {
+		t.Errorf("report = %+v, want 1 payment", report)
+	}
+}
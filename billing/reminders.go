@@ -0,0 +1,116 @@
+package billing
+
+import (
+	"fmt"
+	"sort"
+	"subscription-tracker/models"
+	"time"
+)
+
+// DefaultReminderWindow is how far ahead upcoming events are reported
+const DefaultReminderWindow = 7 * 24 * time.Hour
+
+// ReminderKind identifies what a reminder is about
+type ReminderKind string
+
+const (
+	ReminderTrialEnding ReminderKind = "trial_ending"
+	ReminderCardExpired ReminderKind = "card_expired"
+	ReminderCancelBy    ReminderKind = "cancel_by"
+	// ReminderPromotionEnding warns that the price jumps to the regular
+	// price once a promotion runs out
+	ReminderPromotionEnding ReminderKind = "promotion_ending"
+)
+
+// Reminder is an upcoming event the user should act on
+type Reminder struct {
+	SubscriptionID string
+	Name           string
+	Kind           ReminderKind
+	Date           time.Time
+	Message        string
+}
+
+// Reminders returns the events falling within the window after now, ordered
+// by date. Events that are already past but still pending are included, as
+// are payment methods that expire before the next payment whenever it is.
+// Payment dates are moved off weekends and holidays in the settings' calendar.
+func Reminders(subs []*models.Subscription, settings models.Settings, now time.Time, window time.Duration) []Reminder {
+	horizon := now.Add(window)
+	calendar := settings.Calendar()
+	methods := settings.PaymentMethods
+
+	var reminders []Reminder
+	for _, sub := range subs {
+		if !sub.IsActive() {
+			continue
+		}
+
+		if trialEnd := sub.TrialEndDate(); !trialEnd.IsZero() && !trialEnd.After(horizon) {
+			reminders = append(reminders, Reminder{
+				SubscriptionID: sub.ID(),
+				Name:           sub.Name(),
+				Kind:           ReminderTrialEnding,
+				Date:           trialEnd,
+				Message: fmt.Sprintf("Trial ends on %s; first charge of %s on %s",
+					trialEnd.Format("2006-01-02"), sub.NextPaymentAmount(), sub.AdjustedPaymentDate(calendar).Format("2006-01-02")),
+			})
+		}
+
+		// Cancel-by deadlines are only worth a reminder until they pass
+		if cancelBy := sub.CancelBy(calendar); !cancelBy.IsZero() && !cancelBy.After(horizon) && !sub.CancelByMissed(now, calendar) {
+			reminders = append(reminders, Reminder{
+				SubscriptionID: sub.ID(),
+				Name:           sub.Name(),
+				Kind:           ReminderCancelBy,
+				Date:           cancelBy,
+				Message: fmt.Sprintf("Cancel by %s to avoid the renewal of %s on %s",
+					cancelBy.Format("2006-01-02"), sub.NextPaymentAmount(), sub.AdjustedPaymentDate(calendar).Format("2006-01-02")),
+			})
+		}
+
+		if promotion, ok := sub.Promotion(); ok {
+			if date, price, ok := sub.PromotionEnd(calendar); ok && !date.After(horizon) {
+				reminders = append(reminders, Reminder{
+					SubscriptionID: sub.ID(),
+					Name:           sub.Name(),
+					Kind:           ReminderPromotionEnding,
+					Date:           date,
+					Message: fmt.Sprintf("Promotional price of %s ends; %s is charged from %s",
+						promotion.Price, price, date.Format("2006-01-02")),
+				})
+			}
+		}
+
+		if method, ok := ExpiredPaymentMethod(sub, methods); ok {
+			reminders = append(reminders, Reminder{
+				SubscriptionID: sub.ID(),
+				Name:           sub.Name(),
+				Kind:           ReminderCardExpired,
+				Date:           method.ExpiryEnd(sub.NextPaymentDate().Location()),
+				Message: fmt.Sprintf("%s expires before the next payment on %s",
+					method, sub.NextPaymentDate().Format("2006-01-02")),
+			})
+		}
+	}
+
+	sort.SliceStable(reminders, func(i, j int) bool {
+		return reminders[i].Date.Before(reminders[j].Date)
+	})
+	return reminders
+}
+
+// ExpiredPaymentMethod returns the subscription's payment method when it
+// expires before the next payment is due
+func ExpiredPaymentMethod(sub *models.Subscription, methods []models.PaymentMethod) (models.PaymentMethod, bool) {
+	id := sub.PaymentMethodID()
+	if id == "" || !sub.IsActive() {
+		return models.PaymentMethod{}, false
+	}
+	for _, method := range methods {
+		if method.ID == id {
+			return method, method.ExpiresBefore(sub.NextPaymentDate())
+		}
+	}
+	return models.PaymentMethod{}, false
+}
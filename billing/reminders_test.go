@@ -0,0 +1,32 @@
+package billing
+
+import (
+	"strings"
+	"subscription-tracker/models"
+	"testing"
+)
+
+func TestTrialReminderQuotesFirstCharge(t *testing.T) {
+	next := today().AddDate(0, 0, 30)
+	sub, err := models.NewSubscription("Trial", usd(1000), models.FrequencyMonthly, next, models.OpenEnded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sub.SetTrial(today().AddDate(0, 0, 3)); err != nil {
+		t.Fatal(err)
+	}
+	// The price goes up before the first charge
+	if err := sub.SchedulePriceChange(usd(1200), today().AddDate(0, 0, 20)); err != nil {
+		t.Fatal(err)
+	}
+
+	reminders := Reminders([]*models.Subscription{sub}, models.DefaultSettings(), today(), DefaultReminderWindow)
+	if len(reminders) != 1 || reminders[0].Kind != ReminderTrialEnding {
+		t.Fatalf("reminders = %+v, want one trial reminder", reminders)
+	}
+	calendar := models.DefaultSettings().Calendar()
+	want := "first charge of $12.00 on " + sub.AdjustedPaymentDate(calendar).Format("2006-01-02")
+	if !strings.Contains(reminders[0].Message, want) {
+		t.Errorf("message %q does not mention the %s", reminders[0].Message, want)
+	}
+}
@@ -0,0 +1,72 @@
+package billing
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"subscription-tracker/models"
+)
+
+// MemberItem is one member's monthly share of a single subscription
+type MemberItem struct {
+	Subscription string
+	Monthly      models.Money
+}
+
+// MemberTotal is what one member pays per month across shared subscriptions
+type MemberTotal struct {
+	Name    string
+	Monthly models.Money
+	Items   []MemberItem
+}
+
+// MonthlyShares works out what each member of the active shared subscriptions
+// pays per month in the base currency of the rate table. Your own share is
+// listed under models.OwnerName. Members are ordered by name with your own
+// share last; subscriptions whose currency has no rate are left out and
+// reported in the returned error.
+func MonthlyShares(subs []*models.Subscription, rates models.ExchangeRates) ([]MemberTotal, error) {
+	totals := make(map[string]*MemberTotal)
+	var missing []string
+
+	for _, sub := range subs {
+		if !sub.IsActive() || !sub.IsShared() {
+			continue
+		}
+
+		monthlyFactor := sub.PaymentsPerYear() / 12
+		for _, share := range sub.Split() {
+			converted, err := rates.Convert(share.Amount.Scale(monthlyFactor))
+			if err != nil {
+				missing = append(missing, sub.Name())
+				break
+			}
+
+			// Members are matched by name regardless of case
+			key := strings.ToLower(share.Name)
+			total, ok := totals[key]
+			if !ok {
+				total = &MemberTotal{Name: share.Name, Monthly: models.Money{Currency: rates.Base}}
+				totals[key] = total
+			}
+			total.Monthly.Amount += converted.Amount
+			total.Items = append(total.Items, MemberItem{Subscription: sub.Name(), Monthly: converted})
+		}
+	}
+
+	members := make([]MemberTotal, 0, len(totals))
+	for _, total := range totals {
+		members = append(members, *total)
+	}
+	sort.Slice(members, func(i, j int) bool {
+		if members[i].Name == models.OwnerName || members[j].Name == models.OwnerName {
+			return members[j].Name == models.OwnerName
+		}
+		return strings.ToLower(members[i].Name) < strings.ToLower(members[j].Name)
+	})
+
+	if len(missing) > 0 {
+		return members, fmt.Errorf("missing exchange rates for: %s", strings.Join(missing, ", "))
+	}
+	return members, nil
+}
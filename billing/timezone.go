@@ -0,0 +1,52 @@
+package billing
+
+import (
+	"errors"
+	"fmt"
+	"subscription-tracker/models"
+	"subscription-tracker/storage"
+	"time"
+)
+
+// moveAttempts is how often a subscription is moved again after a payment
+// run updated it at the same time
+const moveAttempts = 3
+
+// MoveToLocation moves the calendar dates of every subscription from the time
+// zone from to the same days in to, so that a payment due on the 1st stays
+// due on the 1st after the time zone setting changes. When a subscription
+// cannot be moved, those already moved are moved back to from.
+func MoveToLocation(store storage.Storage, from, to *time.Location) error {
+	var moved []string
+	for _, sub := range store.GetSubscriptions() {
+		if err := moveSubscription(store, sub.ID(), to); err != nil {
+			err = fmt.Errorf("failed to move '%s' to %s: %v", sub.Name(), to, err)
+			for _, id := range moved {
+				if rollbackErr := moveSubscription(store, id, from); rollbackErr != nil {
+					return fmt.Errorf("%v; moving back to %s also failed: %v", err, from, rollbackErr)
+				}
+			}
+			return err
+		}
+		moved = append(moved, sub.ID())
+	}
+	return nil
+}
+
+// moveSubscription moves a single subscription's dates to loc, starting over
+// from the stored version when it was updated in the meantime
+func moveSubscription(store storage.Storage, id string, loc *time.Location) error {
+	var err error
+	for attempt := 0; attempt < moveAttempts; attempt++ {
+		var sub *models.Subscription
+		if sub, err = store.GetSubscription(id); err != nil {
+			return err
+		}
+		updatedSub := sub.Clone()
+		updatedSub.InLocation(loc)
+		if err = store.UpdateSubscription(id, updatedSub); !errors.Is(err, storage.ErrStale) {
+			return err
+		}
+	}
+	return err
+}
@@ -0,0 +1,73 @@
+package billing
+
+import (
+	"fmt"
+	"subscription-tracker/models"
+	"subscription-tracker/storage"
+	"testing"
+	"time"
+)
+
+// racingStorage fails updates of one subscription and stores a concurrent
+// update of every other one just before it is first updated
+type racingStorage struct {
+	storage.Storage
+	failID string
+	raced  map[string]bool
+}
+
+func (s *racingStorage) UpdateSubscription(id string, updatedSub *models.Subscription) error {
+	if id == s.failID {
+		return fmt.Errorf("disk full")
+	}
+	if !s.raced[id] {
+		s.raced[id] = true
+		stored, err := s.Storage.GetSubscription(id)
+		if err != nil {
+			return err
+		}
+		if err := s.Storage.UpdateSubscription(id, stored.Clone()); err != nil {
+			return err
+		}
+	}
+	return s.Storage.UpdateSubscription(id, updatedSub)
+}
+
+func TestMoveToLocation(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skip(err)
+	}
+	_, store := newTestProcessor(t)
+	first := addSubscription(t, store, "First", 10, models.OpenEnded, nil)
+	second := addSubscription(t, store, "Second", 20, models.OpenEnded, nil)
+	racing := &racingStorage{Storage: store, raced: map[string]bool{}}
+
+	// Payment runs updating the subscriptions at the same time are retried
+	if err := MoveToLocation(racing, time.UTC, tokyo); err != nil {
+		t.Fatal(err)
+	}
+	for _, sub := range []*models.Subscription{first, second} {
+		stored, err := store.GetSubscription(sub.ID())
+		if err != nil {
+			t.Fatal(err)
+		}
+		next := stored.NextPaymentDate()
+		if next.Location() != tokyo || next.Format("2006-01-02") != sub.NextPaymentDate().Format("2006-01-02") {
+			t.Errorf("%s: next payment %s, want %s in Tokyo", sub.Name(), next, sub.NextPaymentDate().Format("2006-01-02"))
+		}
+	}
+
+	// A failure moves the subscriptions moved so far back
+	racing.failID = second.ID()
+	if err := MoveToLocation(racing, tokyo, time.UTC); err == nil {
+		t.Fatal("MoveToLocation succeeded although an update failed")
+	}
+	stored, err := store.GetSubscription(first.ID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.NextPaymentDate().Location() != tokyo {
+		t.Errorf("%s was left in %s", first.Name(), stored.NextPaymentDate().Location())
+	}
+}
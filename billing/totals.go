@@ -0,0 +1,78 @@
+package billing
+
+import (
+	"fmt"
+	"strings"
+	"subscription-tracker/models"
+)
+
+// TotalsByFrequency adds up the cost of every active subscription per payment
+// frequency, converted into the base currency of the rate table.
+// Subscriptions following a recurrence rule have no frequency and are added
+// up separately as their cost per year. Subscriptions whose currency has no
+// rate are left out and reported in the returned error.
+func TotalsByFrequency(subs []*models.Subscription, rates models.ExchangeRates) (map[models.Frequency]models.Money, models.Money, error) {
+	totals := make(map[models.Frequency]models.Money)
+	recurring := models.Money{Currency: rates.Base}
+	var missing []string
+
+	for _, sub := range subs {
+		if !sub.IsActive() {
+			continue
+		}
+
+		if sub.Recurrence() != "" {
+			converted, err := rates.Convert(sub.YearlyCost())
+			if err != nil {
+				missing = append(missing, sub.Name())
+				continue
+			}
+			recurring.Amount += converted.Amount
+			continue
+		}
+
+		converted, err := rates.Convert(sub.Cost())
+		if err != nil {
+			missing = append(missing, sub.Name())
+			continue
+		}
+
+		frequency := sub.PaymentFrequency()
+		total, ok := totals[frequency]
+		if !ok {
+			total = models.Money{Currency: rates.Base}
+		}
+		total.Amount += converted.Amount
+		totals[frequency] = total
+	}
+
+	if len(missing) > 0 {
+		return totals, recurring, fmt.Errorf("missing exchange rates for: %s", strings.Join(missing, ", "))
+	}
+	return totals, recurring, nil
+}
+
+// TotalPer adds up the average cost per period of every active subscription,
+// converted into the base currency of the rate table. Subscriptions whose
+// currency has no rate are left out and reported in the returned error.
+func TotalPer(subs []*models.Subscription, rates models.ExchangeRates, period models.Period) (models.Money, error) {
+	total := models.Money{Currency: rates.Base}
+	var missing []string
+
+	for _, sub := range subs {
+		if !sub.IsActive() {
+			continue
+		}
+		converted, err := rates.Convert(sub.CostPer(period))
+		if err != nil {
+			missing = append(missing, sub.Name())
+			continue
+		}
+		total.Amount += converted.Amount
+	}
+
+	if len(missing) > 0 {
+		return total, fmt.Errorf("missing exchange rates for: %s", strings.Join(missing, ", "))
+	}
+	return total, nil
+}
@@ -0,0 +1,60 @@
+package billing
+
+import (
+	"subscription-tracker/models"
+	"testing"
+)
+
+func TestTotalsByFrequency(t *testing.T) {
+	newSub := func(name string, cost models.Money, frequency models.Frequency) *models.Subscription {
+		t.Helper()
+		sub, err := models.NewSubscription(name, cost, frequency, today().AddDate(0, 0, 10), models.OpenEnded)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return sub
+	}
+	twiceMonthly := newSub("Cleaning", usd(1000), models.FrequencyMonthly)
+	rule, err := models.ParseRRule("FREQ=MONTHLY;BYMONTHDAY=1,15")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The rule starts on its first occurrence
+	start, _ := rule.From(today(), today())
+	if err := twiceMonthly.SetRecurrence(rule.String(), start); err != nil {
+		t.Fatal(err)
+	}
+	paused := newSub("Paused", usd(700), models.FrequencyMonthly)
+	if err := paused.Pause(today().AddDate(0, 2, 0)); err != nil {
+		t.Fatal(err)
+	}
+	subs := []*models.Subscription{
+		newSub("Music", usd(1000), models.FrequencyMonthly),
+		newSub("Video", models.Money{Amount: 1000, Currency: "EUR"}, models.FrequencyMonthly),
+		newSub("Storage", usd(5000), models.FrequencyYearly),
+		twiceMonthly,
+		paused,
+	}
+	rates := models.ExchangeRates{Base: "USD", Rates: map[string]float64{"EUR": 1.1}}
+
+	totals, recurring, err := TotalsByFrequency(subs, rates)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(totals) != 2 || totals[models.FrequencyMonthly] != usd(2100) || totals[models.FrequencyYearly] != usd(5000) {
+		t.Errorf("totals = %v, want monthly $21.00 and yearly $50.00", totals)
+	}
+	// Twice a month for a year, not one monthly charge
+	if recurring != usd(24000) {
+		t.Errorf("recurrence rule total = %s, want $240.00", recurring)
+	}
+
+	// Subscriptions without a rate are reported and left out
+	totals, _, err = TotalsByFrequency(subs, models.ExchangeRates{Base: "USD"})
+	if err == nil {
+		t.Error("missing rate was not reported")
+	}
+	if totals[models.FrequencyMonthly] != usd(1000) {
+		t.Errorf("monthly total = %s, want $10.00", totals[models.FrequencyMonthly])
+	}
+}
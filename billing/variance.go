@@ -0,0 +1,131 @@
+package billing
+
+import (
+	"fmt"
+	"strings"
+	"subscription-tracker/models"
+	"time"
+)
+
+// VarianceTotal adds up estimates and actual amounts in the base currency
+type VarianceTotal struct {
+	Estimate models.Money
+	Actual   models.Money
+	Payments int
+}
+
+// Difference returns how much more than estimated was paid, negative when
+// less was paid
+func (t VarianceTotal) Difference() models.Money {
+	return models.Money{Amount: t.Actual.Amount - t.Estimate.Amount, Currency: t.Actual.Currency}
+}
+
+// Percent returns the difference as a percentage of the estimate
+func (t VarianceTotal) Percent() float64 {
+	if t.Estimate.Amount == 0 {
+		return 0
+	}
+	return float64(t.Difference().Amount) / float64(t.Estimate.Amount) * 100
+}
+
+func (t *VarianceTotal) add(estimate, actual models.Money) {
+	t.Estimate.Amount += estimate.Amount
+	t.Actual.Amount += actual.Amount
+	t.Payments++
+}
+
+// SubscriptionVariance totals the variance of one variable subscription
+type SubscriptionVariance struct {
+	SubscriptionID string
+	Name           string
+	VarianceTotal
+	// Pending counts payments still waiting for their actual amount
+	Pending int
+}
+
+// MonthVariance totals the variance of all variable payments in a month
+type MonthVariance struct {
+	Start time.Time
+	VarianceTotal
+}
+
+// VarianceReport compares the estimated and actual amounts of variable
+// subscriptions over a number of months up to now
+type VarianceReport struct {
+	From          time.Time
+	Until         time.Time
+	Base          string
+	Subscriptions []SubscriptionVariance
+	Months        []MonthVariance
+}
+
+// BuildVarianceReport compares estimate and actual amount of every payment
+// with a known actual amount in the current and previous months, in the
+// base currency. Months follow the configured time zone. Payments whose
+// currency has no rate are left out and reported in the returned error.
+func BuildVarianceReport(subs []*models.Subscription, settings models.Settings, now time.Time, months int) (VarianceReport, error) {
+	rates := settings.ExchangeRates
+	now = now.In(settings.Location())
+	report := VarianceReport{
+		From:  monthStart(now).AddDate(0, 1-months, 0),
+		Until: now,
+		Base:  rates.Base,
+	}
+	empty := VarianceTotal{Estimate: models.Money{Currency: rates.Base}, Actual: models.Money{Currency: rates.Base}}
+	for month := report.From; !month.After(now); month = month.AddDate(0, 1, 0) {
+		report.Months = append(report.Months, MonthVariance{Start: month, VarianceTotal: empty})
+	}
+
+	var missing []string
+	for _, sub := range subs {
+		pending := 0
+		for _, payment := range sub.PendingPayments() {
+			if !payment.Date.Before(report.From) && !payment.Date.After(now) {
+				pending++
+			}
+		}
+		variances := sub.Variances(report.From, now.Add(time.Nanosecond))
+		if len(variances) == 0 && pending == 0 {
+			continue
+		}
+
+		total := SubscriptionVariance{SubscriptionID: sub.ID(), Name: sub.Name(), VarianceTotal: empty, Pending: pending}
+		for _, variance := range variances {
+			estimate, err := rates.Convert(variance.Estimate)
+			if err != nil {
+				missing = append(missing, sub.Name())
+				break
+			}
+			actual, err := rates.Convert(variance.Actual)
+			if err != nil {
+				missing = append(missing, sub.Name())
+				break
+			}
+			total.add(estimate, actual)
+
+			start := monthStart(variance.Date.In(now.Location()))
+			for i := range report.Months {
+				if report.Months[i].Start.Equal(start) {
+					report.Months[i].add(estimate, actual)
+				}
+			}
+		}
+		report.Subscriptions = append(report.Subscriptions, total)
+	}
+
+	if len(missing) > 0 {
+		return report, fmt.Errorf("missing exchange rates for: %s", strings.Join(missing, ", "))
+	}
+	return report, nil
+}
+
+// Total returns the variance over the whole report
+func (r VarianceReport) Total() VarianceTotal {
+	total := VarianceTotal{Estimate: models.Money{Currency: r.Base}, Actual: models.Money{Currency: r.Base}}
+	for _, month := range r.Months {
+		total.Estimate.Amount += month.Estimate.Amount
+		total.Actual.Amount += month.Actual.Amount
+		total.Payments += month.Payments
+	}
+	return total
+}
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// OverallBudget is the category of a budget that caps all spending
+const OverallBudget = "*"
+
+// Budget caps the monthly spending of a category, or of all subscriptions
+// when the category is OverallBudget
+type Budget struct {
+	Category string
+	Monthly  Money
+}
+
+func (b Budget) Validate() error {
+	if strings.TrimSpace(b.Category) == "" {
+		return fmt.Errorf("budget category cannot be empty")
+	}
+	if !b.Monthly.IsPositive() {
+		return fmt.Errorf("budget for %s must be greater than 0", b.Label())
+	}
+	if !ValidCurrency(b.Monthly.Currency) {
+		return fmt.Errorf("unsupported budget currency '%s'", b.Monthly.Currency)
+	}
+	return nil
+}
+
+// IsOverall reports whether the budget covers all subscriptions
+func (b Budget) IsOverall() bool {
+	return b.Category == OverallBudget
+}
+
+// Label returns the category name, or "Overall" for the overall budget
+func (b Budget) Label() string {
+	if b.IsOverall() {
+		return "Overall"
+	}
+	return b.Category
+}
+
+// Covers reports whether the subscription counts towards the budget
+func (b Budget) Covers(sub *Subscription) bool {
+	return b.IsOverall() || strings.EqualFold(CategoryLabel(sub), b.Category)
+}
+
+// ParseBudget parses a budget written as "Category=amount" with an optional
+// currency code after the amount, e.g. "Streaming=50" or "*=300 EUR"
+func ParseBudget(value string, defaultCurrency string) (Budget, error) {
+	category, limit, ok := strings.Cut(value, "=")
+	if !ok {
+		return Budget{}, fmt.Errorf("expected Category=amount, got '%s'", value)
+	}
+
+	fields := strings.Fields(limit)
+	currency := defaultCurrency
+	switch len(fields) {
+	case 1:
+	case 2:
+		currency = strings.ToUpper(fields[1])
+	default:
+		return Budget{}, fmt.Errorf("expected an amount and optional currency, got '%s'", strings.TrimSpace(limit))
+	}
+	if !ValidCurrency(currency) {
+		return Budget{}, fmt.Errorf("unsupported budget currency '%s'", currency)
+	}
+
+	amount, err := ParseMoney(fields[0], currency)
+	if err != nil {
+		return Budget{}, err
+	}
+	budget := Budget{Category: strings.TrimSpace(category), Monthly: amount}
+	if err := budget.Validate(); err != nil {
+		return Budget{}, err
+	}
+	return budget, nil
+}
+
+// FormatBudget writes a budget in the form read by ParseBudget
+func FormatBudget(budget Budget) string {
+	return fmt.Sprintf("%s=%s %s", budget.Category, budget.Monthly.FormatAmount(), budget.Monthly.Currency)
+}
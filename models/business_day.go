@@ -0,0 +1,170 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// RollConvention decides how a payment date that falls on a weekend or
+// holiday is moved to a business day
+type RollConvention string
+
+// Valid roll conventions. RollNone keeps payments on their nominal dates.
+const (
+	RollNone              RollConvention = ""
+	RollFollowing         RollConvention = "following"
+	RollPreceding         RollConvention = "preceding"
+	RollModifiedFollowing RollConvention = "modified_following"
+)
+
+// RollConventions lists the valid conventions in display order
+var RollConventions = []RollConvention{RollNone, RollFollowing, RollPreceding, RollModifiedFollowing}
+
+func (c RollConvention) Validate() error {
+	for _, valid := range RollConventions {
+		if c == valid {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid roll convention '%s': must be following, preceding or modified_following", c)
+}
+
+// String returns a readable name, e.g. "modified following"
+func (c RollConvention) String() string {
+	if c == RollNone {
+		return "none"
+	}
+	return strings.ReplaceAll(string(c), "_", " ")
+}
+
+// maxRollDays bounds the search for a business day so that a calendar
+// without any business days cannot loop forever
+const maxRollDays = 366
+
+// Holiday is a day on which no payments are made
+type Holiday struct {
+	Date time.Time
+	Name string
+}
+
+func (h Holiday) Validate() error {
+	if h.Date.IsZero() {
+		return fmt.Errorf("holiday date cannot be empty")
+	}
+	return nil
+}
+
+// HolidayCalendar holds the days besides weekends that are not business days.
+// A nil calendar only skips weekends.
+type HolidayCalendar struct {
+	holidays map[string]Holiday
+}
+
+// NewHolidayCalendar builds a calendar from the given holidays. Holidays are
+// matched by calendar date, whatever their time of day or time zone.
+func NewHolidayCalendar(holidays []Holiday) *HolidayCalendar {
+	calendar := &HolidayCalendar{holidays: make(map[string]Holiday, len(holidays))}
+	for _, holiday := range holidays {
+		calendar.holidays[dateKey(holiday.Date)] = holiday
+	}
+	return calendar
+}
+
+func dateKey(date time.Time) string {
+	return date.Format("2006-01-02")
+}
+
+// Holiday returns the holiday on the date, if any
+func (c *HolidayCalendar) Holiday(date time.Time) (Holiday, bool) {
+	if c == nil {
+		return Holiday{}, false
+	}
+	holiday, ok := c.holidays[dateKey(date)]
+	return holiday, ok
+}
+
+// IsBusinessDay reports whether the date is neither a weekend nor a holiday
+func (c *HolidayCalendar) IsBusinessDay(date time.Time) bool {
+	if date.Weekday() == time.Saturday || date.Weekday() == time.Sunday {
+		return false
+	}
+	_, holiday := c.Holiday(date)
+	return !holiday
+}
+
+// Adjust moves the date to a business day using the convention. Dates that
+// are already business days, and all dates under RollNone, are unchanged.
+func (c *HolidayCalendar) Adjust(date time.Time, convention RollConvention) time.Time {
+	switch convention {
+	case RollFollowing:
+		return c.roll(date, 1)
+	case RollPreceding:
+		return c.roll(date, -1)
+	case RollModifiedFollowing:
+		// Roll back instead when rolling forward would leave the month
+		if following := c.roll(date, 1); following.Month() == date.Month() {
+			return following
+		}
+		return c.roll(date, -1)
+	default:
+		return date
+	}
+}
+
+// roll steps the date a day at a time in the direction until it reaches a
+// business day
+func (c *HolidayCalendar) roll(date time.Time, step int) time.Time {
+	for i := 0; i < maxRollDays && !c.IsBusinessDay(date); i++ {
+		date = date.AddDate(0, 0, step)
+	}
+	return date
+}
+
+// ParseHoliday parses a holiday written as "YYYY-MM-DD" optionally followed
+// by its name, e.g. "2026-12-25 Christmas Day"
+func ParseHoliday(value string) (Holiday, error) {
+	dateText, name, _ := strings.Cut(strings.TrimSpace(value), " ")
+	date, err := time.Parse("2006-01-02", dateText)
+	if err != nil {
+		return Holiday{}, fmt.Errorf("invalid holiday date '%s': use YYYY-MM-DD", dateText)
+	}
+	return Holiday{Date: date, Name: strings.TrimSpace(name)}, nil
+}
+
+// FormatHoliday writes a holiday in the form read by ParseHoliday
+func FormatHoliday(holiday Holiday) string {
+	if holiday.Name == "" {
+		return dateKey(holiday.Date)
+	}
+	return dateKey(holiday.Date) + " " + holiday.Name
+}
+
+// RollConvention returns how payment dates are moved off weekends and holidays
+func (s *Subscription) RollConvention() RollConvention {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.rollConvention
+}
+
+// SetRollConvention changes how payment dates are moved off weekends and
+// holidays. The nominal schedule itself is unchanged.
+func (s *Subscription) SetRollConvention(convention RollConvention) error {
+	if err := convention.Validate(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rollConvention = convention
+	return nil
+}
+
+// AdjustedPaymentDate returns the date the next payment is actually made on:
+// the nominal next payment date moved to a business day by the roll
+// convention
+func (s *Subscription) AdjustedPaymentDate(calendar *HolidayCalendar) time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return calendar.Adjust(s.nextPaymentDate, s.rollConvention)
+}
@@ -0,0 +1,87 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAdjust(t *testing.T) {
+	// Friday 2027-04-30 is a holiday in this calendar
+	calendar := NewHolidayCalendar([]Holiday{{Date: date(2027, 4, 30), Name: "Bank Holiday"}})
+	tests := []struct {
+		date       time.Time
+		convention RollConvention
+		want       time.Time
+	}{
+		{date(2027, 5, 1), RollNone, date(2027, 5, 1)},
+		{date(2027, 5, 1), RollFollowing, date(2027, 5, 3)},
+		{date(2027, 5, 1), RollPreceding, date(2027, 4, 29)},
+		{date(2027, 5, 1), RollModifiedFollowing, date(2027, 5, 3)},
+		{date(2027, 4, 30), RollFollowing, date(2027, 5, 3)},
+		{date(2027, 4, 30), RollModifiedFollowing, date(2027, 4, 29)},
+		{date(2027, 7, 31), RollModifiedFollowing, date(2027, 7, 30)},
+		{date(2027, 5, 4), RollPreceding, date(2027, 5, 4)},
+	}
+	for _, tt := range tests {
+		if got := calendar.Adjust(tt.date, tt.convention); !got.Equal(tt.want) {
+			t.Errorf("Adjust(%s, %q) = %s, want %s", tt.date.Format("2006-01-02"), tt.convention,
+				got.Format("2006-01-02"), tt.want.Format("2006-01-02"))
+		}
+	}
+}
+
+func TestDeadlinesFollowAdjustedDates(t *testing.T) {
+	// Saturday 2027-05-01 is charged on Friday 2027-04-30 with preceding roll
+	saturday := date(2027, 5, 1)
+	newSub := func(totalPayments int) *Subscription {
+		// Restoring accepts the fixed date once it has passed
+		snap := newTestSubscription(t, usd(999), 1, totalPayments).Snapshot()
+		snap.NextPaymentDate = saturday
+		snap.AnchorDay = saturday.Day()
+		sub, err := RestoreSubscription(snap)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := sub.SetRollConvention(RollPreceding); err != nil {
+			t.Fatal(err)
+		}
+		return sub
+	}
+
+	sub := newSub(OpenEnded)
+	if err := sub.SetNoticeDays(7); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := sub.CancelBy(nil), date(2027, 4, 23); !got.Equal(want) {
+		t.Errorf("CancelBy = %s, want %s", got.Format("2006-01-02"), want.Format("2006-01-02"))
+	}
+	if sub.CancelByMissed(date(2027, 4, 23).Add(12*time.Hour), nil) {
+		t.Error("cancel-by day counted as missed during the day")
+	}
+	if !sub.CancelByMissed(date(2027, 4, 24), nil) {
+		t.Error("cancel-by day not missed the day after")
+	}
+
+	plan := newSub(1)
+	if err := plan.SetInstallment(usd(50000), 0); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := plan.PayoffDate(nil), date(2027, 4, 30); !got.Equal(want) {
+		t.Errorf("PayoffDate = %s, want %s", got.Format("2006-01-02"), want.Format("2006-01-02"))
+	}
+
+	promo := newSub(OpenEnded)
+	if err := promo.SetPromotion(Promotion{Price: usd(199), Payments: 1}); err != nil {
+		t.Fatal(err)
+	}
+	// The second payment, nominally on Tuesday 2027-06-01, is a business day
+	if got, _, _ := promo.PromotionEnd(nil); !got.Equal(date(2027, 6, 1)) {
+		t.Errorf("PromotionEnd = %s, want 2027-06-01", got.Format("2006-01-02"))
+	}
+	if err := promo.SetPromotion(Promotion{Price: usd(199), EndDate: date(2027, 4, 15)}); err != nil {
+		t.Fatal(err)
+	}
+	if got, _, _ := promo.PromotionEnd(nil); !got.Equal(date(2027, 4, 30)) {
+		t.Errorf("PromotionEnd = %s, want 2027-04-30", got.Format("2006-01-02"))
+	}
+}
@@ -0,0 +1,172 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Uncategorized is the group of subscriptions without a category
+const Uncategorized = "Uncategorized"
+
+// Category returns the subscription's category, or an empty string when it
+// has none
+func (s *Subscription) Category() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.category
+}
+
+// Tags returns the subscription's tags in alphabetical order
+func (s *Subscription) Tags() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]string(nil), s.tags...)
+}
+
+// HasTag reports whether the subscription carries the tag, ignoring case
+func (s *Subscription) HasTag(tag string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	tag = normalizeTag(tag)
+	for _, t := range s.tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// SetCategory sets the category. An empty category removes it.
+func (s *Subscription) SetCategory(category string) error {
+	category = strings.TrimSpace(category)
+	if strings.EqualFold(category, Uncategorized) {
+		category = ""
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.category = category
+	return nil
+}
+
+// SetTags replaces the tags. Tags are stored in lower case without
+// duplicates; blank tags are dropped.
+func (s *Subscription) SetTags(tags []string) error {
+	normalized, err := normalizeTags(tags)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tags = normalized
+	return nil
+}
+
+// ParseTags splits a comma separated list of tags
+func ParseTags(value string) []string {
+	if strings.TrimSpace(value) == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
+
+func normalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
+func normalizeTags(tags []string) ([]string, error) {
+	seen := make(map[string]bool, len(tags))
+	var normalized []string
+	for _, tag := range tags {
+		tag = normalizeTag(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		if strings.Contains(tag, ",") {
+			return nil, fmt.Errorf("tag '%s' cannot contain a comma", tag)
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	sort.Strings(normalized)
+	return normalized, nil
+}
+
+// Filter selects subscriptions by category and tag. Empty fields match
+// every subscription.
+type Filter struct {
+	Category string
+	Tag      string
+}
+
+// IsEmpty reports whether the filter matches every subscription
+func (f Filter) IsEmpty() bool {
+	return f.Category == "" && f.Tag == ""
+}
+
+// Matches reports whether the subscription passes the filter. Categories are
+// compared ignoring case and Uncategorized matches subscriptions without one.
+func (f Filter) Matches(sub *Subscription) bool {
+	if f.Category != "" && !strings.EqualFold(CategoryLabel(sub), f.Category) {
+		return false
+	}
+	return f.Tag == "" || sub.HasTag(f.Tag)
+}
+
+// String describes the filter, e.g. "category Streaming, tag family"
+func (f Filter) String() string {
+	var parts []string
+	if f.Category != "" {
+		parts = append(parts, "category "+f.Category)
+	}
+	if f.Tag != "" {
+		parts = append(parts, "tag "+normalizeTag(f.Tag))
+	}
+	if len(parts) == 0 {
+		return "none"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// FilterSubscriptions returns the subscriptions that pass the filter
+func FilterSubscriptions(subs []*Subscription, filter Filter) []*Subscription {
+	if filter.IsEmpty() {
+		return subs
+	}
+	var matched []*Subscription
+	for _, sub := range subs {
+		if filter.Matches(sub) {
+			matched = append(matched, sub)
+		}
+	}
+	return matched
+}
+
+// CategoryLabel returns the category of the subscription, or Uncategorized
+func CategoryLabel(sub *Subscription) string {
+	if category := sub.Category(); category != "" {
+		return category
+	}
+	return Uncategorized
+}
+
+// GroupByCategory groups subscriptions by category label. The categories are
+// returned in alphabetical order with Uncategorized last.
+func GroupByCategory(subs []*Subscription) ([]string, map[string][]*Subscription) {
+	groups := make(map[string][]*Subscription)
+	var categories []string
+	for _, sub := range subs {
+		category := CategoryLabel(sub)
+		if _, ok := groups[category]; !ok {
+			categories = append(categories, category)
+		}
+		groups[category] = append(groups[category], sub)
+	}
+	sort.Slice(categories, func(i, j int) bool {
+		if categories[i] == Uncategorized || categories[j] == Uncategorized {
+			return categories[j] == Uncategorized && categories[i] != Uncategorized
+		}
+		return strings.ToLower(categories[i]) < strings.ToLower(categories[j])
+	})
+	return categories, groups
+}
@@ -0,0 +1,132 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// ExchangeRates is a locally maintained table of conversion rates into a base
+// currency. Each rate is the value of one unit of a currency expressed in the
+// base currency, e.g. with base EUR a rate of 0.92 for USD means 1 USD = 0.92 EUR.
+type ExchangeRates struct {
+	Base  string
+	Date  time.Time
+	Rates map[string]float64
+}
+
+func NewExchangeRates(base string, date time.Time, rates map[string]float64) (ExchangeRates, error) {
+	table := ExchangeRates{Base: base, Date: date, Rates: make(map[string]float64, len(rates))}
+	for currency, rate := range rates {
+		table.Rates[currency] = rate
+	}
+	if err := table.Validate(); err != nil {
+		return ExchangeRates{}, err
+	}
+	return table, nil
+}
+
+// Validate checks that all currencies are supported and all rates are positive
+func (r ExchangeRates) Validate() error {
+	var validationErrors []string
+
+	if !ValidCurrency(r.Base) {
+		validationErrors = append(validationErrors, fmt.Sprintf("unsupported base currency '%s'", r.Base))
+	}
+	for _, currency := range r.Currencies() {
+		if !ValidCurrency(currency) {
+			validationErrors = append(validationErrors, fmt.Sprintf("unsupported currency '%s'", currency))
+		}
+		if rate := r.Rates[currency]; rate <= 0 || math.IsInf(rate, 0) || math.IsNaN(rate) {
+			validationErrors = append(validationErrors, fmt.Sprintf("rate for %s must be greater than 0", currency))
+		}
+	}
+
+	if len(validationErrors) > 0 {
+		return &ValidationError{Errors: validationErrors}
+	}
+	return nil
+}
+
+// Currencies returns the currencies in the table, sorted by code
+func (r ExchangeRates) Currencies() []string {
+	currencies := make([]string, 0, len(r.Rates))
+	for currency := range r.Rates {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+	return currencies
+}
+
+// Rate returns the value of one unit of the currency in the base currency
+func (r ExchangeRates) Rate(currency string) (float64, error) {
+	if currency == r.Base {
+		return 1, nil
+	}
+	rate, ok := r.Rates[currency]
+	if !ok {
+		return 0, fmt.Errorf("no exchange rate for %s to %s", currency, r.Base)
+	}
+	return rate, nil
+}
+
+// Convert converts the amount into the base currency, rounding to the
+// nearest minor unit of the base currency
+func (r ExchangeRates) Convert(m Money) (Money, error) {
+	if m.Currency == r.Base {
+		return m, nil
+	}
+
+	rate, err := r.Rate(m.Currency)
+	if err != nil {
+		return Money{}, err
+	}
+	fromDecimals, err := CurrencyDecimals(m.Currency)
+	if err != nil {
+		return Money{}, err
+	}
+	toDecimals, err := CurrencyDecimals(r.Base)
+	if err != nil {
+		return Money{}, err
+	}
+
+	converted := float64(m.Amount) * rate * math.Pow10(toDecimals-fromDecimals)
+	return Money{Amount: int64(math.Round(converted)), Currency: r.Base}, nil
+}
+
+// Total converts every amount into the base currency and adds them up
+func (r ExchangeRates) Total(amounts ...Money) (Money, error) {
+	total := Money{Currency: r.Base}
+	for _, amount := range amounts {
+		converted, err := r.Convert(amount)
+		if err != nil {
+			return Money{}, err
+		}
+		total.Amount += converted.Amount
+	}
+	return total, nil
+}
+
+// Rebase returns an equivalent table expressed in a different base currency.
+// The new base must either be the current base or have a rate in the table.
+func (r ExchangeRates) Rebase(base string) (ExchangeRates, error) {
+	if base == r.Base {
+		return r, nil
+	}
+
+	baseRate, err := r.Rate(base)
+	if err != nil {
+		return ExchangeRates{}, err
+	}
+
+	rebased := ExchangeRates{Base: base, Date: r.Date, Rates: make(map[string]float64, len(r.Rates))}
+	for currency, rate := range r.Rates {
+		if currency == base {
+			continue
+		}
+		rebased.Rates[currency] = rate / baseRate
+	}
+	rebased.Rates[r.Base] = 1 / baseRate
+	return rebased, nil
+}
@@ -0,0 +1,67 @@
+package models
+
+import "time"
+
+// ScheduledPayment is a payment the subscription is expected to make
+type ScheduledPayment struct {
+	Date   time.Time
+	Amount Money
+}
+
+// Forecast returns the payments expected in [from, until) by walking the
+// schedule with the same rules the payment engine uses: remaining payments,
+// recurrence rules, trials and scheduled price changes. Paused subscriptions
+// contribute from their resume date, skipping payments missed while paused;
+// cancelled and completed ones contribute nothing. Overdue payments that the
+// engine has not processed yet are left out. Payments are dated on the
+// business day the roll convention moves them to in the calendar.
+func (s *Subscription) Forecast(from, until time.Time, calendar *HolidayCalendar) []ScheduledPayment {
+	// Walk a copy so the subscription itself is left untouched
+	sim := s.Clone()
+
+	switch sim.State() {
+	case StatePaused:
+		resume := sim.ResumeDate()
+		if resume.IsZero() || !resume.Before(until) {
+			return nil
+		}
+		if err := sim.Resume(resume); err != nil {
+			return nil
+		}
+	case StateActive:
+	default:
+		return nil
+	}
+
+	var payments []ScheduledPayment
+	for sim.IsDue(until, calendar) {
+		date := sim.AdjustedPaymentDate(calendar)
+		if !date.Before(until) {
+			break
+		}
+		amount := sim.NextPaymentAmount()
+		if err := sim.ProcessPayment(calendar); err != nil {
+			break
+		}
+		if !date.Before(from) {
+			payments = append(payments, ScheduledPayment{Date: date, Amount: amount})
+		}
+	}
+	return payments
+}
+
+// NextPaymentAmount returns what the next payment is expected to charge.
+// Prices follow the nominal date, as they do when the payment is made, a
+// running promotion replaces the price, and the last installment of a plan
+// settles what rounding left over.
+func (s *Subscription) NextPaymentAmount() Money {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.installment != nil {
+		return s.installmentAmount()
+	}
+	if s.promotion != nil && s.promotion.appliesTo(s.nextPaymentDate) {
+		return s.promotion.Price
+	}
+	return s.priceOn(s.nextPaymentDate)
+}
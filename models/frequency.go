@@ -0,0 +1,177 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FrequencyUnit is the calendar unit a billing interval is counted in
+type FrequencyUnit string
+
+// Valid frequency units
+const (
+	UnitDay   FrequencyUnit = "day"
+	UnitWeek  FrequencyUnit = "week"
+	UnitMonth FrequencyUnit = "month"
+	UnitYear  FrequencyUnit = "year"
+)
+
+// FrequencyUnits lists the valid units from shortest to longest
+var FrequencyUnits = []FrequencyUnit{UnitDay, UnitWeek, UnitMonth, UnitYear}
+
+// Common payment frequencies
+var (
+	FrequencyDaily      = Frequency{Unit: UnitDay, Interval: 1}
+	FrequencyWeekly     = Frequency{Unit: UnitWeek, Interval: 1}
+	FrequencyMonthly    = Frequency{Unit: UnitMonth, Interval: 1}
+	FrequencyQuarterly  = Frequency{Unit: UnitMonth, Interval: 3}
+	FrequencySemiannual = Frequency{Unit: UnitMonth, Interval: 6}
+	FrequencyYearly     = Frequency{Unit: UnitYear, Interval: 1}
+)
+
+// namedFrequencies maps the names accepted by ParseFrequency to their frequency
+var namedFrequencies = map[string]Frequency{
+	"daily":      FrequencyDaily,
+	"weekly":     FrequencyWeekly,
+	"biweekly":   {Unit: UnitWeek, Interval: 2},
+	"monthly":    FrequencyMonthly,
+	"quarterly":  FrequencyQuarterly,
+	"semiannual": FrequencySemiannual,
+	"yearly":     FrequencyYearly,
+	"annual":     FrequencyYearly,
+}
+
+// Frequency describes how often a subscription is billed as a number of
+// calendar units, e.g. every 3 months or every 4 weeks
+type Frequency struct {
+	Unit     FrequencyUnit
+	Interval int
+}
+
+func NewFrequency(unit FrequencyUnit, interval int) (Frequency, error) {
+	f := Frequency{Unit: unit, Interval: interval}
+	if err := f.Validate(); err != nil {
+		return Frequency{}, err
+	}
+	return f, nil
+}
+
+// ParseFrequency parses a frequency name such as "monthly" or "quarterly",
+// or an explicit interval such as "every 2 weeks" or "6 months"
+func ParseFrequency(value string) (Frequency, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if f, ok := namedFrequencies[value]; ok {
+		return f, nil
+	}
+
+	fields := strings.Fields(strings.TrimPrefix(value, "every "))
+	if len(fields) == 1 {
+		// "every week" is the same as "every 1 week"
+		fields = []string{"1", fields[0]}
+	}
+	if len(fields) != 2 {
+		return Frequency{}, fmt.Errorf("invalid payment frequency '%s'", value)
+	}
+
+	interval, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return Frequency{}, fmt.Errorf("invalid payment frequency '%s'", value)
+	}
+	return NewFrequency(FrequencyUnit(strings.TrimSuffix(fields[1], "s")), interval)
+}
+
+func (f Frequency) Validate() error {
+	switch f.Unit {
+	case UnitDay, UnitWeek, UnitMonth, UnitYear:
+	default:
+		return fmt.Errorf("invalid payment frequency unit '%s': must be one of day, week, month, or year", f.Unit)
+	}
+	if f.Interval <= 0 {
+		return fmt.Errorf("payment frequency interval must be greater than 0")
+	}
+	return nil
+}
+
+// String returns a readable name, e.g. "monthly", "quarterly" or "every 4 weeks"
+func (f Frequency) String() string {
+	switch f {
+	case FrequencyDaily:
+		return "daily"
+	case FrequencyWeekly:
+		return "weekly"
+	case FrequencyMonthly:
+		return "monthly"
+	case FrequencyQuarterly:
+		return "quarterly"
+	case FrequencySemiannual:
+		return "semiannual"
+	case FrequencyYearly:
+		return "yearly"
+	}
+	return fmt.Sprintf("every %d %ss", f.Interval, f.Unit)
+}
+
+// Compare orders frequencies by unit and then by interval, returning a
+// negative number, zero or a positive number like strings.Compare
+func (f Frequency) Compare(other Frequency) int {
+	if f.Unit != other.Unit {
+		return unitRank(f.Unit) - unitRank(other.Unit)
+	}
+	return f.Interval - other.Interval
+}
+
+func unitRank(unit FrequencyUnit) int {
+	for i, u := range FrequencyUnits {
+		if u == unit {
+			return i
+		}
+	}
+	return len(FrequencyUnits)
+}
+
+// Next returns the date one billing interval after the given date. Month and
+// year steps are clamped to the last day of shorter months.
+func (f Frequency) Next(current time.Time) time.Time {
+	return f.NextAnchored(current, current.Day())
+}
+
+// NextAnchored returns the date one billing interval after the given date,
+// placing month and year steps on the anchor day. When the target month is
+// too short the last day of the month is used instead, so a subscription
+// anchored on the 31st goes Jan 31, Feb 28, Mar 31 rather than drifting.
+func (f Frequency) NextAnchored(current time.Time, anchorDay int) time.Time {
+	switch f.Unit {
+	case UnitDay:
+		return current.AddDate(0, 0, f.Interval)
+	case UnitWeek:
+		return current.AddDate(0, 0, 7*f.Interval)
+	case UnitMonth:
+		return addMonthsClamped(current, f.Interval, anchorDay)
+	case UnitYear:
+		return addMonthsClamped(current, 12*f.Interval, anchorDay)
+	default:
+		return current
+	}
+}
+
+// addMonthsClamped adds months to the date and moves it to the anchor day
+// without overflowing into the following month, e.g. Jan 31 + 1 month is
+// Feb 28 (or 29 in leap years)
+func addMonthsClamped(current time.Time, months int, anchorDay int) time.Time {
+	year, month, _ := current.Date()
+	// Normalize through time.Date on the first of the month to handle year rollover
+	target := time.Date(year, month+time.Month(months), 1, 0, 0, 0, 0, current.Location())
+	lastDay := daysIn(target.Year(), target.Month(), current.Location())
+	day := anchorDay
+	if day > lastDay {
+		day = lastDay
+	}
+	return time.Date(target.Year(), target.Month(), day, current.Hour(), current.Minute(), current.Second(), current.Nanosecond(), current.Location())
+}
+
+// daysIn returns the number of days in the month
+func daysIn(year int, month time.Month, loc *time.Location) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, loc).Day()
+}
@@ -0,0 +1,77 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNextAnchored(t *testing.T) {
+	tests := []struct {
+		frequency Frequency
+		current   time.Time
+		anchorDay int
+		want      time.Time
+	}{
+		{FrequencyMonthly, date(2027, 1, 31), 31, date(2027, 2, 28)},
+		{FrequencyMonthly, date(2027, 2, 28), 31, date(2027, 3, 31)},
+		{FrequencyMonthly, date(2027, 2, 28), 30, date(2027, 3, 30)},
+		{FrequencyMonthly, date(2028, 1, 30), 30, date(2028, 2, 29)},
+		{FrequencyMonthly, date(2027, 12, 15), 15, date(2028, 1, 15)},
+		{FrequencyQuarterly, date(2027, 11, 30), 31, date(2028, 2, 29)},
+		{FrequencyYearly, date(2028, 2, 29), 29, date(2029, 2, 28)},
+		{FrequencyYearly, date(2029, 2, 28), 29, date(2030, 2, 28)},
+		{Frequency{Unit: UnitYear, Interval: 4}, date(2029, 2, 28), 29, date(2033, 2, 28)},
+		{FrequencyWeekly, date(2027, 1, 31), 31, date(2027, 2, 7)},
+		{Frequency{Unit: UnitDay, Interval: 10}, date(2027, 2, 25), 25, date(2027, 3, 7)},
+	}
+	for _, tt := range tests {
+		if got := tt.frequency.NextAnchored(tt.current, tt.anchorDay); !got.Equal(tt.want) {
+			t.Errorf("%s after %s anchored on %d = %s, want %s", tt.frequency, tt.current.Format("2006-01-02"),
+				tt.anchorDay, got.Format("2006-01-02"), tt.want.Format("2006-01-02"))
+		}
+	}
+}
+
+func TestAnchorMatches(t *testing.T) {
+	tests := []struct {
+		date      time.Time
+		anchorDay int
+		want      bool
+	}{
+		{date(2027, 3, 31), 31, true},
+		{date(2027, 4, 30), 31, true},
+		{date(2027, 2, 28), 30, true},
+		{date(2028, 2, 28), 30, false},
+		{date(2028, 2, 29), 31, true},
+		{date(2027, 4, 30), 30, true},
+		{date(2027, 3, 30), 31, false},
+		{date(2027, 3, 15), 14, false},
+	}
+	for _, tt := range tests {
+		if got := anchorMatches(tt.date, tt.anchorDay); got != tt.want {
+			t.Errorf("anchorMatches(%s, %d) = %t, want %t", tt.date.Format("2006-01-02"), tt.anchorDay, got, tt.want)
+		}
+	}
+}
+
+func TestPaymentsKeepAnchorDay(t *testing.T) {
+	// New subscriptions must start in the future, so use next year's January
+	year := time.Now().Year() + 1
+	sub, err := NewSubscription("Test", usd(999), FrequencyMonthly, date(year, 1, 31), OpenEnded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	february := date(year, 2, daysIn(year, time.February, time.UTC))
+	want := []time.Time{february, date(year, 3, 31), date(year, 4, 30), date(year, 5, 31)}
+	for _, next := range want {
+		if err := sub.ProcessPayment(nil); err != nil {
+			t.Fatal(err)
+		}
+		if got := sub.NextPaymentDate(); !got.Equal(next) {
+			t.Fatalf("next payment on %s, want %s", got.Format("2006-01-02"), next.Format("2006-01-02"))
+		}
+	}
+	if sub.AnchorDay() != 31 {
+		t.Errorf("anchor day drifted to %d", sub.AnchorDay())
+	}
+}
@@ -0,0 +1,291 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// maxAPR bounds the annual percentage rate of an installment plan
+const maxAPR = 100
+
+// InstallmentPlan turns a subscription with a fixed number of payments into
+// a loan that pays off a principal at an annual percentage rate. The payment
+// is the subscription's cost, derived from the principal, rate and number of
+// payments.
+type InstallmentPlan struct {
+	Principal Money
+	// APR is the annual percentage rate, e.g. 19.99 for 19.99%
+	APR float64
+	// PaidOffOn is set when the remaining balance was paid off early
+	PaidOffOn time.Time
+	// PaidOffAfter is the number of scheduled payments made before then
+	PaidOffAfter int
+}
+
+func (p InstallmentPlan) Validate() error {
+	if !p.Principal.IsPositive() {
+		return fmt.Errorf("installment principal must be greater than 0")
+	}
+	if !ValidCurrency(p.Principal.Currency) {
+		return fmt.Errorf("unsupported principal currency '%s'", p.Principal.Currency)
+	}
+	if p.APR < 0 || p.APR > maxAPR || math.IsNaN(p.APR) {
+		return fmt.Errorf("APR must be between 0 and %d%%", maxAPR)
+	}
+	if p.PaidOffAfter < 0 {
+		return fmt.Errorf("payments made before payoff cannot be negative")
+	}
+	return nil
+}
+
+// IsPaidOff reports whether the plan was closed by an early payoff
+func (p InstallmentPlan) IsPaidOff() bool {
+	return !p.PaidOffOn.IsZero()
+}
+
+// AmortizationRow is one scheduled payment of an installment plan
+type AmortizationRow struct {
+	Number    int
+	Payment   Money
+	Interest  Money
+	Principal Money
+	// Balance is the principal still owed after the payment
+	Balance Money
+}
+
+// InstallmentPayment returns the fixed payment that pays off the principal
+// at the APR in the given number of payments of the frequency
+func InstallmentPayment(principal Money, apr float64, frequency Frequency, payments int) (Money, error) {
+	plan := InstallmentPlan{Principal: principal, APR: apr}
+	if err := plan.Validate(); err != nil {
+		return Money{}, err
+	}
+	if err := frequency.Validate(); err != nil {
+		return Money{}, err
+	}
+	if payments <= 0 {
+		return Money{}, fmt.Errorf("installment plans need a fixed number of payments")
+	}
+
+	rate := periodRate(apr, frequency)
+	if rate == 0 {
+		return Money{Amount: int64(math.Ceil(float64(principal.Amount) / float64(payments))), Currency: principal.Currency}, nil
+	}
+	amount := float64(principal.Amount) * rate / (1 - math.Pow(1+rate, -float64(payments)))
+	return Money{Amount: int64(math.Round(amount)), Currency: principal.Currency}, nil
+}
+
+// periodRate converts the APR to the interest rate of a single period
+func periodRate(apr float64, frequency Frequency) float64 {
+	return apr / 100 / frequency.PaymentsPerYear()
+}
+
+// amortize lays out every payment of the plan. The last payment settles
+// whatever is left after rounding.
+func amortize(plan InstallmentPlan, payment Money, frequency Frequency, payments int) []AmortizationRow {
+	rate := periodRate(plan.APR, frequency)
+	currency := plan.Principal.Currency
+	balance := plan.Principal.Amount
+
+	rows := make([]AmortizationRow, 0, payments)
+	for number := 1; number <= payments && balance > 0; number++ {
+		interest := int64(math.Round(float64(balance) * rate))
+		amount := payment.Amount
+		if number == payments || amount > balance+interest {
+			amount = balance + interest
+		}
+		balance -= amount - interest
+		rows = append(rows, AmortizationRow{
+			Number:    number,
+			Payment:   Money{Amount: amount, Currency: currency},
+			Interest:  Money{Amount: interest, Currency: currency},
+			Principal: Money{Amount: amount - interest, Currency: currency},
+			Balance:   Money{Amount: balance, Currency: currency},
+		})
+	}
+	return rows
+}
+
+// Installment returns the subscription's installment plan, if it has one
+func (s *Subscription) Installment() (InstallmentPlan, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.installment == nil {
+		return InstallmentPlan{}, false
+	}
+	return *s.installment, true
+}
+
+// SetInstallment makes the subscription an installment plan paying off the
+// principal at the APR over its total payments. The cost becomes the
+// plan's fixed payment.
+func (s *Subscription) SetInstallment(principal Money, apr float64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.installment != nil && s.installment.IsPaidOff() {
+		return fmt.Errorf("the installment plan was already paid off")
+	}
+	if s.totalPayments == OpenEnded {
+		return fmt.Errorf("installment plans need a fixed number of payments")
+	}
+	if s.recurrence != nil {
+		return fmt.Errorf("installment plans follow a billing frequency, not a recurrence rule")
+	}
+	if s.variable {
+		return fmt.Errorf("installment plans have a fixed payment and cannot be variable")
+	}
+	if len(s.scheduledPrices) > 0 {
+		return fmt.Errorf("installment plans have a fixed payment; cancel the scheduled price change first")
+	}
+	if s.promotion != nil {
+		return fmt.Errorf("installment plans have a fixed payment; end the promotion first")
+	}
+	if err := validateParticipants(s.participants, principal.Currency); err != nil {
+		return err
+	}
+
+	payment, err := InstallmentPayment(principal, apr, s.paymentFrequency, s.totalPayments)
+	if err != nil {
+		return err
+	}
+	s.installment = &InstallmentPlan{Principal: principal, APR: apr}
+	s.cost = payment
+	return nil
+}
+
+// ClearInstallment turns an installment plan back into a plain subscription
+// with a fixed number of payments
+func (s *Subscription) ClearInstallment() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.installment = nil
+}
+
+// Amortization returns the full payment schedule of an installment plan, or
+// nil for other subscriptions
+func (s *Subscription) Amortization() []AmortizationRow {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.installment == nil {
+		return nil
+	}
+	return amortize(*s.installment, s.cost, s.paymentFrequency, s.totalPayments)
+}
+
+// installmentsMade returns how many scheduled payments of the plan were made
+func (s *Subscription) installmentsMade() int {
+	if s.installment.IsPaidOff() {
+		return s.installment.PaidOffAfter
+	}
+	return s.totalPayments - s.remainingPayments
+}
+
+// RemainingBalance returns the principal still owed on an installment plan
+func (s *Subscription) RemainingBalance() Money {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.remainingBalance()
+}
+
+func (s *Subscription) remainingBalance() Money {
+	if s.installment == nil {
+		return Money{}
+	}
+	if s.installment.IsPaidOff() {
+		return Money{Currency: s.installment.Principal.Currency}
+	}
+	return balanceAfter(s.installment.Principal, amortize(*s.installment, s.cost, s.paymentFrequency, s.totalPayments), s.installmentsMade())
+}
+
+// balanceAfter returns the balance once the first made payments are paid
+func balanceAfter(principal Money, rows []AmortizationRow, made int) Money {
+	if made <= 0 || len(rows) == 0 {
+		return principal
+	}
+	if made > len(rows) {
+		made = len(rows)
+	}
+	return rows[made-1].Balance
+}
+
+// InterestPaid returns the interest included in the installments made so far
+func (s *Subscription) InterestPaid() Money {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.installment == nil {
+		return Money{}
+	}
+
+	rows := amortize(*s.installment, s.cost, s.paymentFrequency, s.totalPayments)
+	paid := Money{Currency: s.installment.Principal.Currency}
+	for i := 0; i < s.installmentsMade() && i < len(rows); i++ {
+		paid.Amount += rows[i].Interest.Amount
+	}
+	return paid
+}
+
+// PayoffDate returns the business day in the calendar the last installment
+// is charged on, or the date the plan was paid off early. It is zero for
+// other subscriptions.
+func (s *Subscription) PayoffDate(calendar *HolidayCalendar) time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	switch {
+	case s.installment == nil:
+		return time.Time{}
+	case s.installment.IsPaidOff():
+		return s.installment.PaidOffOn
+	case s.hasEnded():
+		if n := len(s.payments); n > 0 {
+			return s.payments[n-1].Date
+		}
+		return time.Time{}
+	}
+
+	date := s.nextPaymentDate
+	for i := 1; i < s.remainingPayments; i++ {
+		date = s.paymentFrequency.NextAnchored(date, s.anchorDay)
+	}
+	return calendar.Adjust(date, s.rollConvention)
+}
+
+// PayOff pays the remaining balance of an installment plan on the given date
+// and closes the plan. No further installments are charged.
+func (s *Subscription) PayOff(date time.Time) error {
+	if date.IsZero() {
+		return fmt.Errorf("payoff date cannot be empty")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.installment == nil {
+		return fmt.Errorf("subscription is not an installment plan")
+	}
+	if s.hasEnded() {
+		return fmt.Errorf("the installment plan is already closed")
+	}
+	if s.state != StateActive {
+		return fmt.Errorf("subscription is %s", s.state)
+	}
+
+	balance := s.remainingBalance()
+	s.recordPayment(Payment{Date: date, Amount: balance, Source: PaymentSourceManual})
+	s.installment.PaidOffAfter = s.installmentsMade()
+	s.installment.PaidOffOn = date
+	s.remainingPayments = 0
+	return nil
+}
+
+// installmentAmount returns the scheduled amount of the next installment,
+// which differs from the fixed payment only for the last one
+func (s *Subscription) installmentAmount() Money {
+	rows := amortize(*s.installment, s.cost, s.paymentFrequency, s.totalPayments)
+	if made := s.installmentsMade(); made < len(rows) {
+		return rows[made].Payment
+	}
+	return s.cost
+}
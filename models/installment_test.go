@@ -0,0 +1,141 @@
+package models
+
+import (
+	"testing"
+)
+
+func TestInstallmentAmortization(t *testing.T) {
+	tests := []struct {
+		name      string
+		principal Money
+		apr       float64
+		frequency Frequency
+		payments  int
+		payment   int64
+		last      int64
+		interest  int64
+	}{
+		{"interest free", usd(120000), 0, FrequencyMonthly, 12, 10000, 10000, 0},
+		{"interest free rounding", usd(100000), 0, FrequencyMonthly, 3, 33334, 33332, 0},
+		{"monthly 12%", usd(120000), 12, FrequencyMonthly, 12, 10662, 10660, 7942},
+		{"single payment", usd(50000), 24, FrequencyMonthly, 1, 51000, 51000, 1000},
+		{"zero decimal currency", Money{Amount: 100000, Currency: "JPY"}, 0, FrequencyMonthly, 3, 33334, 33332, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payment, err := InstallmentPayment(tt.principal, tt.apr, tt.frequency, tt.payments)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if payment.Amount != tt.payment {
+				t.Errorf("payment = %d, want %d", payment.Amount, tt.payment)
+			}
+
+			rows := amortize(InstallmentPlan{Principal: tt.principal, APR: tt.apr}, payment, tt.frequency, tt.payments)
+			if len(rows) != tt.payments {
+				t.Fatalf("got %d rows, want %d", len(rows), tt.payments)
+			}
+			var principal, interest int64
+			for _, row := range rows {
+				principal += row.Principal.Amount
+				interest += row.Interest.Amount
+				if row.Payment.Amount != row.Principal.Amount+row.Interest.Amount {
+					t.Errorf("row %d: payment %d is not principal plus interest", row.Number, row.Payment.Amount)
+				}
+			}
+			last := rows[len(rows)-1]
+			if last.Payment.Amount != tt.last || last.Balance.Amount != 0 {
+				t.Errorf("last row pays %d leaving %d, want %d leaving 0", last.Payment.Amount, last.Balance.Amount, tt.last)
+			}
+			if principal != tt.principal.Amount {
+				t.Errorf("principal repaid = %d, want %d", principal, tt.principal.Amount)
+			}
+			if interest != tt.interest {
+				t.Errorf("interest = %d, want %d", interest, tt.interest)
+			}
+		})
+	}
+}
+
+func TestInstallmentPaymentErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		principal Money
+		apr       float64
+		payments  int
+	}{
+		{"no principal", usd(0), 5, 12},
+		{"negative APR", usd(1000), -1, 12},
+		{"APR too high", usd(1000), 101, 12},
+		{"open-ended", usd(1000), 5, OpenEnded},
+	}
+	for _, tt := range tests {
+		if _, err := InstallmentPayment(tt.principal, tt.apr, FrequencyMonthly, tt.payments); err == nil {
+			t.Errorf("%s: InstallmentPayment succeeded, want an error", tt.name)
+		}
+	}
+}
+
+func TestInstallmentPlanRejectsScheduleEdits(t *testing.T) {
+	sub := newTestSubscription(t, usd(1000), 5, 12)
+	if err := sub.SetInstallment(usd(120000), 12); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		edit func() error
+	}{
+		{"open-ended", func() error { return sub.SetTotalPayments(OpenEnded) }},
+		{"fewer payments", func() error { return sub.SetTotalPayments(6) }},
+		{"cost", func() error { return sub.SetCost(usd(5000)) }},
+		{"frequency", func() error { return sub.SetPaymentFrequency(FrequencyWeekly) }},
+		{"variable", func() error { return sub.SetVariable(true) }},
+		{"recurrence", func() error { return sub.SetRecurrence("FREQ=MONTHLY", sub.NextPaymentDate()) }},
+	}
+	for _, tt := range tests {
+		if err := tt.edit(); err == nil {
+			t.Errorf("%s: edit accepted on an installment plan", tt.name)
+		}
+	}
+
+	// Unchanged values are accepted, as the edit form sends them back
+	if err := sub.SetTotalPayments(12); err != nil {
+		t.Errorf("SetTotalPayments(12) = %v", err)
+	}
+	if err := sub.SetCost(sub.Cost()); err != nil {
+		t.Errorf("SetCost(unchanged) = %v", err)
+	}
+
+	restored := restore(t, sub)
+	if got := restored.Cost().Amount; got != 10662 {
+		t.Errorf("restored payment = %d, want 10662", got)
+	}
+}
+
+func TestInstallmentPayOff(t *testing.T) {
+	sub := newTestSubscription(t, usd(1000), 5, 12)
+	if err := sub.SetInstallment(usd(120000), 12); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if err := sub.ProcessPayment(nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	balance := sub.RemainingBalance()
+	if want := amortize(InstallmentPlan{Principal: usd(120000), APR: 12}, usd(10662), FrequencyMonthly, 12)[2].Balance; balance != want {
+		t.Fatalf("balance after 3 payments = %s, want %s", balance, want)
+	}
+
+	if err := sub.PayOff(sub.NextPaymentDate()); err != nil {
+		t.Fatal(err)
+	}
+	payments := sub.Payments()
+	if got := payments[len(payments)-1].Amount; got != balance {
+		t.Errorf("payoff charged %s, want the balance %s", got, balance)
+	}
+	if sub.RemainingBalance().Amount != 0 || !sub.HasEnded() {
+		t.Errorf("plan still open after payoff: balance %s, ended %v", sub.RemainingBalance(), sub.HasEnded())
+	}
+	restore(t, sub)
+}
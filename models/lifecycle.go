@@ -0,0 +1,180 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// State is the lifecycle state of a subscription
+type State string
+
+// Valid lifecycle states. Completed is reached on its own once no payments
+// are left; the other states are entered through the transition methods.
+const (
+	StateActive    State = "active"
+	StatePaused    State = "paused"
+	StateCancelled State = "cancelled"
+	StateCompleted State = "completed"
+)
+
+// transitions lists the states each state may move to
+var transitions = map[State][]State{
+	StateActive:    {StatePaused, StateCancelled},
+	StatePaused:    {StateActive, StateCancelled},
+	StateCancelled: {StateActive},
+	StateCompleted: {},
+}
+
+// CanTransition reports whether a subscription may move between the states
+func CanTransition(from, to State) bool {
+	for _, allowed := range transitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// State returns the lifecycle state of the subscription
+func (s *Subscription) State() State {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.currentState()
+}
+
+func (s *Subscription) currentState() State {
+	if s.state != StateActive {
+		return s.state
+	}
+	if s.hasEnded() {
+		return StateCompleted
+	}
+	return StateActive
+}
+
+// IsActive reports whether the subscription is still being charged
+func (s *Subscription) IsActive() bool {
+	return s.State() == StateActive
+}
+
+// ResumeDate returns the date a paused subscription resumes on its own, or
+// the zero time when it stays paused until resumed by hand
+func (s *Subscription) ResumeDate() time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.resumeDate
+}
+
+// AccessEndDate returns the date access ends for a cancelled subscription
+func (s *Subscription) AccessEndDate() time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.accessEndDate
+}
+
+func (s *Subscription) transition(to State) error {
+	from := s.currentState()
+	if !CanTransition(from, to) {
+		return fmt.Errorf("cannot change a %s subscription to %s", from, to)
+	}
+	s.state = to
+	s.resumeDate = time.Time{}
+	s.accessEndDate = time.Time{}
+	return nil
+}
+
+// Pause stops payments until the subscription is resumed. With a resume date
+// the payment engine resumes it on that date; a zero date pauses it until
+// Resume is called.
+func (s *Subscription) Pause(resume time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.transition(StatePaused); err != nil {
+		return err
+	}
+	s.resumeDate = resume
+	return nil
+}
+
+// Resume reactivates a paused subscription. Payments that fell due while it
+// was paused are skipped rather than charged, so the next payment is the
+// first one after now.
+func (s *Subscription) Resume(now time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.currentState() != StatePaused {
+		return fmt.Errorf("cannot resume a %s subscription", s.currentState())
+	}
+	if err := s.transition(StateActive); err != nil {
+		return err
+	}
+	return s.skipMissedPayments(now)
+}
+
+// ResumeIfDue resumes a paused subscription whose resume date has been
+// reached and reports whether it did
+func (s *Subscription) ResumeIfDue(now time.Time) (bool, error) {
+	s.mu.RLock()
+	due := s.state == StatePaused && !s.resumeDate.IsZero() && !s.resumeDate.After(now)
+	s.mu.RUnlock()
+
+	if !due {
+		return false, nil
+	}
+	return true, s.Resume(now)
+}
+
+// Cancel stops all further payments while keeping the subscription for
+// history. Access continues until accessEnd; a zero date keeps access until
+// the next payment would have been due.
+func (s *Subscription) Cancel(accessEnd time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if accessEnd.IsZero() {
+		accessEnd = s.nextPaymentDate
+	}
+	if err := s.transition(StateCancelled); err != nil {
+		return err
+	}
+	s.accessEndDate = accessEnd
+	return nil
+}
+
+// Reactivate restarts a cancelled subscription with its next payment on the
+// given date
+func (s *Subscription) Reactivate(nextPayment time.Time) error {
+	if nextPayment.IsZero() {
+		return fmt.Errorf("next payment date cannot be empty")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.currentState() != StateCancelled {
+		return fmt.Errorf("cannot reactivate a %s subscription", s.currentState())
+	}
+	if err := s.transition(StateActive); err != nil {
+		return err
+	}
+	s.nextPaymentDate = nextPayment
+	s.anchorDay = nextPayment.Day()
+	return nil
+}
+
+// skipMissedPayments moves the schedule past now without charging anything
+func (s *Subscription) skipMissedPayments(now time.Time) error {
+	for !s.hasEnded() && s.nextPaymentDate.Before(now) {
+		next, ok := s.calculateNextPaymentDate()
+		if !ok {
+			s.ended = true
+			return nil
+		}
+		if !next.After(s.nextPaymentDate) {
+			return fmt.Errorf("unable to advance payment date for frequency '%s'", s.paymentFrequency)
+		}
+		s.advanceTo(next)
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// DefaultCurrency is used when no currency is specified
+const DefaultCurrency = "USD"
+
+// currencyDecimals maps ISO 4217 currency codes to their number of minor unit digits
+var currencyDecimals = map[string]int{
+	"AUD": 2, "BHD": 3, "BRL": 2, "CAD": 2, "CHF": 2, "CLP": 0, "CNY": 2,
+	"CZK": 2, "DKK": 2, "EUR": 2, "GBP": 2, "HKD": 2, "HUF": 2, "IDR": 2,
+	"ILS": 2, "INR": 2, "ISK": 0, "JOD": 3, "JPY": 0, "KRW": 0, "KWD": 3,
+	"MXN": 2, "MYR": 2, "NOK": 2, "NZD": 2, "OMR": 3, "PHP": 2, "PLN": 2,
+	"RON": 2, "SEK": 2, "SGD": 2, "THB": 2, "TND": 3, "TRY": 2, "TWD": 2,
+	"UAH": 2, "USD": 2, "VND": 0, "ZAR": 2,
+}
+
+// currencySymbols holds symbols for currencies that are commonly written with one
+var currencySymbols = map[string]string{
+	"EUR": "€",
+	"GBP": "£",
+	"INR": "₹",
+	"JPY": "¥",
+	"KRW": "₩",
+	"USD": "$",
+}
+
+// ValidCurrency reports whether the currency code is a supported ISO 4217 code
+func ValidCurrency(code string) bool {
+	_, ok := currencyDecimals[code]
+	return ok
+}
+
+// CurrencyDecimals returns the number of minor unit digits for the currency
+func CurrencyDecimals(code string) (int, error) {
+	decimals, ok := currencyDecimals[code]
+	if !ok {
+		return 0, fmt.Errorf("unsupported currency '%s'", code)
+	}
+	return decimals, nil
+}
+
+// Money is an exact amount expressed in the minor units of its currency
+// (e.g. cents for USD), so sums never accumulate rounding errors
+type Money struct {
+	Amount   int64
+	Currency string
+}
+
+func NewMoney(amount int64, currency string) (Money, error) {
+	if !ValidCurrency(currency) {
+		return Money{}, fmt.Errorf("unsupported currency '%s'", currency)
+	}
+	return Money{Amount: amount, Currency: currency}, nil
+}
+
+// ParseMoney parses a decimal string such as "12.99" into minor units of the
+// given currency without going through floating point
+func ParseMoney(value string, currency string) (Money, error) {
+	decimals, err := CurrencyDecimals(currency)
+	if err != nil {
+		return Money{}, err
+	}
+
+	value = strings.TrimSpace(value)
+	negative := strings.HasPrefix(value, "-")
+	value = strings.TrimPrefix(value, "-")
+
+	whole, fraction, hasFraction := strings.Cut(value, ".")
+	if whole == "" && fraction == "" {
+		return Money{}, fmt.Errorf("invalid amount '%s'", value)
+	}
+	if hasFraction && fraction == "" {
+		return Money{}, fmt.Errorf("invalid amount '%s'", value)
+	}
+	if len(fraction) > decimals {
+		return Money{}, fmt.Errorf("%s amounts cannot have more than %d decimal place(s)", currency, decimals)
+	}
+	if whole == "" {
+		whole = "0"
+	}
+
+	digits := whole + fraction + strings.Repeat("0", decimals-len(fraction))
+	for _, r := range digits {
+		if r < '0' || r > '9' {
+			return Money{}, fmt.Errorf("invalid amount '%s'", value)
+		}
+	}
+
+	amount, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil {
+		return Money{}, fmt.Errorf("invalid amount '%s': %v", value, err)
+	}
+	if negative {
+		amount = -amount
+	}
+	return Money{Amount: amount, Currency: currency}, nil
+}
+
+// IsPositive reports whether the amount is greater than zero
+func (m Money) IsPositive() bool {
+	return m.Amount > 0
+}
+
+// Add returns the sum of two amounts in the same currency
+func (m Money) Add(other Money) (Money, error) {
+	if m.Currency != other.Currency {
+		return Money{}, fmt.Errorf("cannot add %s to %s", other.Currency, m.Currency)
+	}
+	return Money{Amount: m.Amount + other.Amount, Currency: m.Currency}, nil
+}
+
+// Scale multiplies the amount by the factor, rounding to the nearest minor unit
+func (m Money) Scale(factor float64) Money {
+	return Money{Amount: int64(math.Round(float64(m.Amount) * factor)), Currency: m.Currency}
+}
+
+// FormatAmount returns the plain decimal amount without symbol or grouping,
+// suitable for editing and parsing back with ParseMoney
+func (m Money) FormatAmount() string {
+	decimals := currencyDecimals[m.Currency]
+	sign := ""
+	amount := m.Amount
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	digits := strconv.FormatInt(amount, 10)
+	if decimals == 0 {
+		return sign + digits
+	}
+	if len(digits) <= decimals {
+		digits = strings.Repeat("0", decimals-len(digits)+1) + digits
+	}
+	split := len(digits) - decimals
+	return sign + digits[:split] + "." + digits[split:]
+}
+
+// String formats the amount with its currency symbol (or code) and
+// thousands separators, e.g. "$1,234.50", "¥1,200" or "CHF 12.00"
+func (m Money) String() string {
+	amount := m.FormatAmount()
+	sign := ""
+	if strings.HasPrefix(amount, "-") {
+		sign = "-"
+		amount = amount[1:]
+	}
+
+	whole, fraction, hasFraction := strings.Cut(amount, ".")
+	var grouped strings.Builder
+	for i, r := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			grouped.WriteByte(',')
+		}
+		grouped.WriteRune(r)
+	}
+	if hasFraction {
+		grouped.WriteString("." + fraction)
+	}
+
+	if symbol, ok := currencySymbols[m.Currency]; ok {
+		return sign + symbol + grouped.String()
+	}
+	return sign + m.Currency + " " + grouped.String()
+}
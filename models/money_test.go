@@ -0,0 +1,79 @@
+package models
+
+import (
+	"testing"
+)
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		value    string
+		currency string
+		want     int64
+	}{
+		{"12.99", "USD", 1299},
+		{"12.9", "USD", 1290},
+		{"12", "USD", 1200},
+		{".5", "USD", 50},
+		{" 0.01 ", "USD", 1},
+		{"-3.50", "EUR", -350},
+		{"1500", "JPY", 1500},
+		{"1.234", "BHD", 1234},
+		{"0.1", "BHD", 100},
+	}
+	for _, tt := range tests {
+		got, err := ParseMoney(tt.value, tt.currency)
+		if err != nil {
+			t.Errorf("ParseMoney(%q, %s) failed: %v", tt.value, tt.currency, err)
+			continue
+		}
+		if got != (Money{Amount: tt.want, Currency: tt.currency}) {
+			t.Errorf("ParseMoney(%q, %s) = %d, want %d", tt.value, tt.currency, got.Amount, tt.want)
+		}
+		// Formatted amounts parse back to the same value
+		if again, err := ParseMoney(got.FormatAmount(), tt.currency); err != nil || again != got {
+			t.Errorf("ParseMoney(%q) = %v, %v after formatting %v", got.FormatAmount(), again, err, got)
+		}
+	}
+}
+
+func TestParseMoneyErrors(t *testing.T) {
+	tests := []struct {
+		value    string
+		currency string
+	}{
+		{"", "USD"},
+		{"-", "USD"},
+		{".", "USD"},
+		{"12.", "USD"},
+		{"12.999", "USD"},
+		{"1.5", "JPY"},
+		{"1,000", "USD"},
+		{"1e3", "USD"},
+		{"--1", "USD"},
+		{"12.99", "XYZ"},
+		{"99999999999999999999", "USD"},
+	}
+	for _, tt := range tests {
+		if got, err := ParseMoney(tt.value, tt.currency); err == nil {
+			t.Errorf("ParseMoney(%q, %s) = %v, want an error", tt.value, tt.currency, got)
+		}
+	}
+}
+
+func TestMoneyString(t *testing.T) {
+	tests := []struct {
+		money Money
+		want  string
+	}{
+		{Money{Amount: 1299, Currency: "USD"}, "$12.99"},
+		{Money{Amount: 5, Currency: "USD"}, "$0.05"},
+		{Money{Amount: -123456789, Currency: "EUR"}, "-€1,234,567.89"},
+		{Money{Amount: 1500, Currency: "JPY"}, "¥1,500"},
+		{Money{Amount: 1234, Currency: "BHD"}, "BHD 1.234"},
+	}
+	for _, tt := range tests {
+		if got := tt.money.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
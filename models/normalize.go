@@ -0,0 +1,107 @@
+package models
+
+import "fmt"
+
+// Average calendar lengths used to compare schedules of different units
+const (
+	daysPerYear  = 365.25
+	weeksPerYear = daysPerYear / 7
+)
+
+// PaymentsPerYear returns the average number of payments a year
+func (f Frequency) PaymentsPerYear() float64 {
+	switch f.Unit {
+	case UnitDay:
+		return daysPerYear / float64(f.Interval)
+	case UnitWeek:
+		return weeksPerYear / float64(f.Interval)
+	case UnitMonth:
+		return 12 / float64(f.Interval)
+	case UnitYear:
+		return 1 / float64(f.Interval)
+	default:
+		return 0
+	}
+}
+
+// PaymentsPerYear returns how many payments the subscription makes a year.
+// Recurrence rules are measured over the year starting at the next payment,
+// so rules that end within the year count only their remaining occurrences.
+func (s *Subscription) PaymentsPerYear() float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.recurrence == nil {
+		return s.paymentFrequency.PaymentsPerYear()
+	}
+
+	end := s.nextPaymentDate.AddDate(1, 0, 0)
+	count := 0
+	for date, ok := s.nextPaymentDate, true; ok && date.Before(end); date, ok = s.recurrence.After(s.recurrenceStart, date) {
+		count++
+	}
+	return float64(count)
+}
+
+// Period is a span of time that costs are normalized to
+type Period string
+
+// Valid normalization periods
+const (
+	PeriodDay   Period = "day"
+	PeriodMonth Period = "month"
+	PeriodYear  Period = "year"
+)
+
+// Periods lists the valid periods from shortest to longest
+var Periods = []Period{PeriodDay, PeriodMonth, PeriodYear}
+
+// DefaultPeriod is the period costs are shown per unless configured otherwise
+const DefaultPeriod = PeriodMonth
+
+func (p Period) Validate() error {
+	if p.perYear() == 0 {
+		return fmt.Errorf("invalid cost period '%s': must be one of day, month, or year", p)
+	}
+	return nil
+}
+
+// perYear returns how many of the period fit in an average year
+func (p Period) perYear() float64 {
+	switch p {
+	case PeriodDay:
+		return daysPerYear
+	case PeriodMonth:
+		return 12
+	case PeriodYear:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// CostPer returns the average cost of the subscription per period at its
+// current price. Averages are taken over a year of 365.25 days so weekly
+// and daily schedules are not skewed by month lengths or leap years.
+func (s *Subscription) CostPer(period Period) Money {
+	perYear := period.perYear()
+	if perYear == 0 {
+		return Money{Currency: s.Cost().Currency}
+	}
+	return s.Cost().Scale(s.PaymentsPerYear() / perYear)
+}
+
+// DailyCost returns the average cost per day
+func (s *Subscription) DailyCost() Money {
+	return s.CostPer(PeriodDay)
+}
+
+// MonthlyCost returns the average cost per month
+func (s *Subscription) MonthlyCost() Money {
+	return s.CostPer(PeriodMonth)
+}
+
+// YearlyCost returns the average cost per year
+func (s *Subscription) YearlyCost() Money {
+	return s.CostPer(PeriodYear)
+}
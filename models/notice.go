@@ -0,0 +1,51 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// NoticeDays returns how many days before a renewal the subscription must be
+// cancelled to avoid it, or 0 when it can be cancelled at any time
+func (s *Subscription) NoticeDays() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.noticeDays
+}
+
+// SetNoticeDays sets the cancellation notice period in days
+func (s *Subscription) SetNoticeDays(days int) error {
+	if days < 0 {
+		return fmt.Errorf("notice period cannot be negative")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.noticeDays = days
+	return nil
+}
+
+// CancelBy returns the last day the subscription can be cancelled to avoid
+// the next renewal, counted back from the business day the renewal is
+// charged on in the calendar. It is the zero time when there is no notice
+// period or no renewal is coming up.
+func (s *Subscription) CancelBy(calendar *HolidayCalendar) time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.noticeDays == 0 || s.currentState() != StateActive {
+		return time.Time{}
+	}
+	return calendar.Adjust(s.nextPaymentDate, s.rollConvention).AddDate(0, 0, -s.noticeDays)
+}
+
+// CancelByMissed reports whether the cancel-by day for the next renewal has
+// passed at the given time
+func (s *Subscription) CancelByMissed(now time.Time, calendar *HolidayCalendar) bool {
+	cancelBy := s.CancelBy(calendar)
+	if cancelBy.IsZero() {
+		return false
+	}
+	// The whole cancel-by day counts
+	year, month, day := cancelBy.Date()
+	return !now.Before(time.Date(year, month, day+1, 0, 0, 0, 0, cancelBy.Location()))
+}
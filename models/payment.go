@@ -0,0 +1,145 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// PaymentSource records how a payment entered the ledger
+type PaymentSource string
+
+// Valid payment sources
+const (
+	PaymentSourceAuto     PaymentSource = "auto"
+	PaymentSourceManual   PaymentSource = "manual"
+	PaymentSourceImported PaymentSource = "imported"
+)
+
+var ValidPaymentSources = map[PaymentSource]bool{
+	PaymentSourceAuto:     true,
+	PaymentSourceManual:   true,
+	PaymentSourceImported: true,
+}
+
+// Payment is a ledger entry for a payment that was made. Payments of
+// variable subscriptions keep the estimate they were made against; until
+// the actual amount is entered they are pending and Amount is the estimate.
+type Payment struct {
+	Date     time.Time
+	Amount   Money
+	Source   PaymentSource
+	Estimate Money
+	Pending  bool
+}
+
+func (p Payment) Validate() error {
+	if p.Date.IsZero() {
+		return fmt.Errorf("payment date cannot be empty")
+	}
+	if !ValidCurrency(p.Amount.Currency) {
+		return fmt.Errorf("unsupported payment currency '%s'", p.Amount.Currency)
+	}
+	if p.Amount.Amount < 0 {
+		return fmt.Errorf("payment amount cannot be negative")
+	}
+	if !ValidPaymentSources[p.Source] {
+		return fmt.Errorf("invalid payment source '%s'", p.Source)
+	}
+	if p.Estimate != (Money{}) && !ValidCurrency(p.Estimate.Currency) {
+		return fmt.Errorf("unsupported estimate currency '%s'", p.Estimate.Currency)
+	}
+	if p.Pending && p.Estimate == (Money{}) {
+		return fmt.Errorf("pending payment has no estimate")
+	}
+	return nil
+}
+
+// Payments returns the payment ledger ordered by date
+func (s *Subscription) Payments() []Payment {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]Payment(nil), s.payments...)
+}
+
+// PaymentsBetween returns the ledger entries dated in [from, to)
+func (s *Subscription) PaymentsBetween(from, to time.Time) []Payment {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var payments []Payment
+	for _, payment := range s.payments {
+		if !payment.Date.Before(from) && payment.Date.Before(to) {
+			payments = append(payments, payment)
+		}
+	}
+	return payments
+}
+
+// TotalPaid adds up the ledger entries dated in [from, to). Amounts are
+// totalled per currency, ordered by currency code.
+func (s *Subscription) TotalPaid(from, to time.Time) []Money {
+	return SumByCurrency(paymentAmounts(s.PaymentsBetween(from, to)))
+}
+
+// ImportPayment adds a historical payment to the ledger without changing
+// the payment schedule. When a payment on the same date is waiting for its
+// actual amount, the imported amount is recorded as that actual instead.
+func (s *Subscription) ImportPayment(payment Payment) error {
+	payment.Source = PaymentSourceImported
+	if err := payment.Validate(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, existing := range s.payments {
+		if existing.Pending && dateKey(existing.Date) == dateKey(payment.Date) && existing.Estimate.Currency == payment.Amount.Currency {
+			s.payments[i].Amount = payment.Amount
+			s.payments[i].Pending = false
+			return nil
+		}
+	}
+	s.recordPayment(payment)
+	return nil
+}
+
+// ProcessManualPayment processes the upcoming payment as paid by hand on the
+// given date, advancing the schedule as an automatic payment would
+func (s *Subscription) ProcessManualPayment(paidOn time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.processPayment(PaymentSourceManual, paidOn)
+}
+
+// recordPayment inserts the payment keeping the ledger ordered by date
+func (s *Subscription) recordPayment(payment Payment) {
+	index := sort.Search(len(s.payments), func(i int) bool {
+		return s.payments[i].Date.After(payment.Date)
+	})
+	s.payments = append(s.payments, Payment{})
+	copy(s.payments[index+1:], s.payments[index:])
+	s.payments[index] = payment
+}
+
+func paymentAmounts(payments []Payment) []Money {
+	amounts := make([]Money, len(payments))
+	for i, payment := range payments {
+		amounts[i] = payment.Amount
+	}
+	return amounts
+}
+
+// SumByCurrency adds up amounts per currency, ordered by currency code
+func SumByCurrency(amounts []Money) []Money {
+	totals := make(map[string]int64)
+	for _, amount := range amounts {
+		totals[amount.Currency] += amount.Amount
+	}
+
+	sums := make([]Money, 0, len(totals))
+	for currency, total := range totals {
+		sums = append(sums, Money{Amount: total, Currency: currency})
+	}
+	sort.Slice(sums, func(i, j int) bool { return sums[i].Currency < sums[j].Currency })
+	return sums
+}
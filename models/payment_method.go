@@ -0,0 +1,166 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// PaymentMethodType is the kind of account a subscription is paid from
+type PaymentMethodType string
+
+// Valid payment method types
+const (
+	MethodCreditCard  PaymentMethodType = "credit_card"
+	MethodDebitCard   PaymentMethodType = "debit_card"
+	MethodBankAccount PaymentMethodType = "bank_account"
+	MethodWallet      PaymentMethodType = "wallet"
+	MethodOther       PaymentMethodType = "other"
+)
+
+// PaymentMethodTypes lists the valid types in display order
+var PaymentMethodTypes = []PaymentMethodType{MethodCreditCard, MethodDebitCard, MethodBankAccount, MethodWallet, MethodOther}
+
+// String returns a readable name, e.g. "credit card"
+func (t PaymentMethodType) String() string {
+	return strings.ReplaceAll(string(t), "_", " ")
+}
+
+// IsCard reports whether methods of this type carry an expiry date
+func (t PaymentMethodType) IsCard() bool {
+	return t == MethodCreditCard || t == MethodDebitCard
+}
+
+// PaymentMethod is a user-defined card or account that subscriptions are
+// paid from. Cards expire at the end of their expiry month; an ExpiryYear of
+// 0 means the method does not expire.
+type PaymentMethod struct {
+	ID          string
+	Label       string
+	Type        PaymentMethodType
+	LastFour    string
+	ExpiryMonth int
+	ExpiryYear  int
+}
+
+func (m PaymentMethod) Validate() error {
+	var validationErrors []string
+
+	if m.ID == "" {
+		validationErrors = append(validationErrors, "payment method ID cannot be empty")
+	}
+	if strings.TrimSpace(m.Label) == "" {
+		validationErrors = append(validationErrors, "payment method label cannot be empty")
+	}
+	validType := false
+	for _, t := range PaymentMethodTypes {
+		validType = validType || t == m.Type
+	}
+	if !validType {
+		validationErrors = append(validationErrors, fmt.Sprintf("invalid payment method type '%s'", m.Type))
+	}
+	if m.LastFour != "" && !isLastFour(m.LastFour) {
+		validationErrors = append(validationErrors, "last four digits must be exactly 4 digits")
+	}
+	if m.HasExpiry() {
+		if m.ExpiryMonth < 1 || m.ExpiryMonth > 12 {
+			validationErrors = append(validationErrors, "expiry month must be between 1 and 12")
+		}
+		if m.ExpiryYear < 2000 || m.ExpiryYear > 9999 {
+			validationErrors = append(validationErrors, "expiry year must be a four digit year")
+		}
+	} else if m.ExpiryMonth != 0 {
+		validationErrors = append(validationErrors, "expiry month requires an expiry year")
+	}
+
+	if len(validationErrors) > 0 {
+		return &ValidationError{Errors: validationErrors}
+	}
+	return nil
+}
+
+func isLastFour(value string) bool {
+	if len(value) != 4 {
+		return false
+	}
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// HasExpiry reports whether the method has an expiry date
+func (m PaymentMethod) HasExpiry() bool {
+	return m.ExpiryYear != 0
+}
+
+// ExpiresBefore reports whether the method can no longer be charged on the
+// given date. Methods without an expiry date never expire.
+func (m PaymentMethod) ExpiresBefore(date time.Time) bool {
+	if !m.HasExpiry() {
+		return false
+	}
+	return !date.Before(m.ExpiryEnd(date.Location()))
+}
+
+// ExpiryEnd returns the first moment after the expiry month in the location
+func (m PaymentMethod) ExpiryEnd(loc *time.Location) time.Time {
+	return time.Date(m.ExpiryYear, time.Month(m.ExpiryMonth)+1, 1, 0, 0, 0, 0, loc)
+}
+
+// FormatExpiry returns the expiry date as MM/YYYY, or an empty string
+func (m PaymentMethod) FormatExpiry() string {
+	if !m.HasExpiry() {
+		return ""
+	}
+	return fmt.Sprintf("%02d/%d", m.ExpiryMonth, m.ExpiryYear)
+}
+
+// String returns a short description, e.g. "Work Visa ••4242 (exp 03/2027)"
+func (m PaymentMethod) String() string {
+	text := m.Label
+	if m.LastFour != "" {
+		text += " ••" + m.LastFour
+	}
+	if m.HasExpiry() {
+		text += " (exp " + m.FormatExpiry() + ")"
+	}
+	return text
+}
+
+// ParseExpiry parses an expiry date written as MM/YYYY or MM/YY. An empty
+// value returns zeros for a method without expiry.
+func ParseExpiry(value string) (month, year int, err error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, 0, nil
+	}
+	if _, err := fmt.Sscanf(value, "%d/%d", &month, &year); err != nil {
+		return 0, 0, fmt.Errorf("invalid expiry date '%s': use MM/YYYY", value)
+	}
+	if year < 100 {
+		year += 2000
+	}
+	if month < 1 || month > 12 {
+		return 0, 0, fmt.Errorf("invalid expiry date '%s': month must be between 1 and 12", value)
+	}
+	return month, year, nil
+}
+
+// PaymentMethodID returns the ID of the payment method the subscription is
+// paid from, or an empty string when none is assigned
+func (s *Subscription) PaymentMethodID() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.paymentMethodID
+}
+
+// SetPaymentMethodID assigns the payment method. An empty ID removes it.
+func (s *Subscription) SetPaymentMethodID(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.paymentMethodID = id
+	return nil
+}
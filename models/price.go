@@ -0,0 +1,175 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// PriceChange is an entry in a subscription's price timeline. Previous holds
+// the price that was replaced and is only set once the change is applied.
+type PriceChange struct {
+	EffectiveDate time.Time
+	Cost          Money
+	Previous      Money
+}
+
+// IsIncrease reports whether an applied change raised the price
+func (c PriceChange) IsIncrease() bool {
+	return c.Previous.Currency == c.Cost.Currency && c.Cost.Amount > c.Previous.Amount
+}
+
+func (c PriceChange) Validate() error {
+	if c.EffectiveDate.IsZero() {
+		return fmt.Errorf("price change effective date cannot be empty")
+	}
+	if !c.Cost.IsPositive() {
+		return fmt.Errorf("price must be greater than 0")
+	}
+	if !ValidCurrency(c.Cost.Currency) {
+		return fmt.Errorf("unsupported currency '%s'", c.Cost.Currency)
+	}
+	return nil
+}
+
+// PriceHistory returns the price changes already applied, oldest first
+func (s *Subscription) PriceHistory() []PriceChange {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]PriceChange(nil), s.priceHistory...)
+}
+
+// ScheduledPrices returns the price changes still to come, soonest first
+func (s *Subscription) ScheduledPrices() []PriceChange {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]PriceChange(nil), s.scheduledPrices...)
+}
+
+// PriceOn returns the price charged for a payment due on the given date,
+// taking scheduled price changes into account
+func (s *Subscription) PriceOn(date time.Time) Money {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.priceOn(date)
+}
+
+func (s *Subscription) priceOn(date time.Time) Money {
+	price := s.cost
+	for _, change := range s.scheduledPrices {
+		if change.EffectiveDate.After(date) {
+			break
+		}
+		price = change.Cost
+	}
+	return price
+}
+
+// RecentPriceIncreases returns the applied price increases that took effect
+// on or after the given date, oldest first
+func (s *Subscription) RecentPriceIncreases(since time.Time) []PriceChange {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var increases []PriceChange
+	for _, change := range s.priceHistory {
+		if !change.EffectiveDate.Before(since) && change.IsIncrease() {
+			increases = append(increases, change)
+		}
+	}
+	return increases
+}
+
+// SchedulePriceChange sets a new price from the given date on. Payments due
+// on or after the date are charged the new price. A change dated in the past
+// takes effect immediately; a change on the same date as an already scheduled
+// one replaces it.
+func (s *Subscription) SchedulePriceChange(cost Money, effective time.Time) error {
+	change := PriceChange{EffectiveDate: effective, Cost: cost}
+	if err := change.Validate(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.installment != nil {
+		return fmt.Errorf("installment plans have a fixed payment")
+	}
+	if err := validateParticipants(s.participants, cost.Currency); err != nil {
+		return err
+	}
+	if err := s.checkPromotionCurrency(cost.Currency); err != nil {
+		return err
+	}
+	if n := len(s.priceHistory); n > 0 && !effective.After(s.priceHistory[n-1].EffectiveDate) {
+		return fmt.Errorf("price change must take effect after the last change on %s", s.priceHistory[n-1].EffectiveDate.Format("2006-01-02"))
+	}
+
+	if !effective.After(time.Now()) {
+		if len(s.scheduledPrices) > 0 && !s.scheduledPrices[0].EffectiveDate.After(effective) {
+			return fmt.Errorf("price change must take effect after the scheduled change on %s", s.scheduledPrices[0].EffectiveDate.Format("2006-01-02"))
+		}
+		s.applyPriceChange(change)
+		return nil
+	}
+
+	s.schedulePrice(change)
+	return nil
+}
+
+// CancelPriceChange removes the price change scheduled for the given date
+func (s *Subscription) CancelPriceChange(effective time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, change := range s.scheduledPrices {
+		if change.EffectiveDate.Equal(effective) {
+			s.scheduledPrices = append(s.scheduledPrices[:i], s.scheduledPrices[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("no price change scheduled for %s", effective.Format("2006-01-02"))
+}
+
+// schedulePrice inserts the change keeping scheduled changes ordered by date
+func (s *Subscription) schedulePrice(change PriceChange) {
+	index := sort.Search(len(s.scheduledPrices), func(i int) bool {
+		return !s.scheduledPrices[i].EffectiveDate.Before(change.EffectiveDate)
+	})
+	if index < len(s.scheduledPrices) && s.scheduledPrices[index].EffectiveDate.Equal(change.EffectiveDate) {
+		s.scheduledPrices[index] = change
+		return
+	}
+	s.scheduledPrices = append(s.scheduledPrices, PriceChange{})
+	copy(s.scheduledPrices[index+1:], s.scheduledPrices[index:])
+	s.scheduledPrices[index] = change
+}
+
+// applyPriceChange makes the change the current price and records it in the
+// price history
+func (s *Subscription) applyPriceChange(change PriceChange) {
+	change.Previous = s.cost
+	s.priceHistory = append(s.priceHistory, change)
+	s.cost = change.Cost
+}
+
+// ApplyPriceChanges applies every scheduled change that has taken effect by
+// the given time and returns the applied changes
+func (s *Subscription) ApplyPriceChanges(now time.Time) []PriceChange {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.applyDuePriceChanges(now)
+}
+
+// applyDuePriceChanges applies every scheduled change effective on or before
+// the given date
+func (s *Subscription) applyDuePriceChanges(date time.Time) []PriceChange {
+	var applied []PriceChange
+	for len(s.scheduledPrices) > 0 && !s.scheduledPrices[0].EffectiveDate.After(date) {
+		s.applyPriceChange(s.scheduledPrices[0])
+		s.scheduledPrices = s.scheduledPrices[1:]
+		applied = append(applied, s.priceHistory[len(s.priceHistory)-1])
+	}
+	return applied
+}
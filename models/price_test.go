@@ -0,0 +1,102 @@
+package models
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func usd(amount int64) Money {
+	return Money{Amount: amount, Currency: "USD"}
+}
+
+// newTestSubscription creates a monthly subscription due days from today
+func newTestSubscription(t *testing.T, cost Money, days int, totalPayments int) *Subscription {
+	t.Helper()
+	sub, err := NewSubscription("Test", cost, FrequencyMonthly, Today(time.Now(), time.UTC).AddDate(0, 0, days), totalPayments)
+	if err != nil {
+		t.Fatalf("NewSubscription: %v", err)
+	}
+	return sub
+}
+
+// restore round-trips the subscription through its snapshot
+func restore(t *testing.T, sub *Subscription) *Subscription {
+	t.Helper()
+	restored, err := RestoreSubscription(sub.Snapshot())
+	if err != nil {
+		t.Fatalf("RestoreSubscription: %v", err)
+	}
+	return restored
+}
+
+func TestEarlyPaymentKeepsFuturePriceScheduled(t *testing.T) {
+	sub := newTestSubscription(t, usd(999), 15, OpenEnded)
+	today := Today(time.Now(), time.UTC)
+	effective := today.AddDate(0, 0, 10)
+	if err := sub.SchedulePriceChange(usd(1299), effective); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := sub.ProcessManualPayment(today); err != nil {
+		t.Fatal(err)
+	}
+	if got := sub.Payments()[0].Amount; got != usd(1299) {
+		t.Errorf("early payment charged %s, want the scheduled price", got)
+	}
+	if len(sub.PriceHistory()) != 0 || len(sub.ScheduledPrices()) != 1 {
+		t.Fatalf("future change left the schedule: history %v, scheduled %v", sub.PriceHistory(), sub.ScheduledPrices())
+	}
+
+	// Editing the cost afterwards must keep the history loadable
+	if err := sub.SetCost(usd(1099)); err != nil {
+		t.Fatal(err)
+	}
+	restored := restore(t, sub)
+	if got := restored.PriceOn(effective); got != usd(1299) {
+		t.Errorf("price on %s = %s, want the scheduled price", effective.Format("2006-01-02"), got)
+	}
+}
+
+func TestSetCostRejectsChangeBeforeHistory(t *testing.T) {
+	snap := newTestSubscription(t, usd(999), 15, OpenEnded).Snapshot()
+	future := time.Now().AddDate(0, 0, 5)
+	snap.PriceHistory = []PriceChange{{EffectiveDate: future, Cost: usd(999), Previous: usd(899)}}
+	sub, err := RestoreSubscription(snap)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = sub.SetCost(usd(1099))
+	if err == nil || !strings.Contains(err.Error(), "already changed") {
+		t.Fatalf("SetCost = %v, want an error about the later change", err)
+	}
+	restore(t, sub)
+}
+
+func TestPriceOn(t *testing.T) {
+	sub := newTestSubscription(t, usd(999), 1, OpenEnded)
+	today := Today(time.Now(), time.UTC)
+	if err := sub.SchedulePriceChange(usd(1299), today.AddDate(0, 2, 0)); err != nil {
+		t.Fatal(err)
+	}
+	if err := sub.SchedulePriceChange(usd(1499), today.AddDate(0, 4, 0)); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		months int
+		want   Money
+	}{
+		{0, usd(999)},
+		{2, usd(1299)},
+		{3, usd(1299)},
+		{4, usd(1499)},
+		{12, usd(1499)},
+	}
+	for _, tt := range tests {
+		if got := sub.PriceOn(today.AddDate(0, tt.months, 0)); got != tt.want {
+			t.Errorf("PriceOn(+%d months) = %s, want %s", tt.months, got, tt.want)
+		}
+	}
+}
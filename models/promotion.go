@@ -0,0 +1,141 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// maxPromotionWalk bounds the payments walked to find where a promotion ends
+const maxPromotionWalk = 1000
+
+// Promotion is an introductory price charged instead of the regular cost for
+// a number of payments, until an end date, or whichever comes first
+type Promotion struct {
+	Price Money
+	// Payments is how many promotional payments are left; 0 means the
+	// promotion only ends on its end date
+	Payments int
+	// EndDate is the first date whose payment is charged the regular price;
+	// zero means the promotion only ends after its payments
+	EndDate time.Time
+}
+
+func (p Promotion) Validate() error {
+	if !p.Price.IsPositive() {
+		return fmt.Errorf("promotional price must be greater than 0")
+	}
+	if !ValidCurrency(p.Price.Currency) {
+		return fmt.Errorf("unsupported promotional price currency '%s'", p.Price.Currency)
+	}
+	if p.Payments < 0 {
+		return fmt.Errorf("promotional payments cannot be negative")
+	}
+	if p.Payments == 0 && p.EndDate.IsZero() {
+		return fmt.Errorf("a promotion needs a number of payments or an end date")
+	}
+	return nil
+}
+
+// appliesTo reports whether a payment due on the date gets the promotional
+// price
+func (p Promotion) appliesTo(date time.Time) bool {
+	return p.EndDate.IsZero() || date.Before(p.EndDate)
+}
+
+// Promotion returns the running promotion, if any
+func (s *Subscription) Promotion() (Promotion, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.promotion == nil {
+		return Promotion{}, false
+	}
+	return *s.promotion, true
+}
+
+// SetPromotion charges the promotional price instead of the cost for the
+// given number of payments or until the end date. The cost stays the regular
+// price that is charged once the promotion ends.
+func (s *Subscription) SetPromotion(promotion Promotion) error {
+	if err := promotion.Validate(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if promotion.Price.Currency != s.cost.Currency {
+		return fmt.Errorf("promotional price must be in %s like the regular price", s.cost.Currency)
+	}
+	for _, change := range s.scheduledPrices {
+		if change.Cost.Currency != promotion.Price.Currency {
+			return fmt.Errorf("promotional price must be in %s like the price change on %s", change.Cost.Currency, change.EffectiveDate.Format("2006-01-02"))
+		}
+	}
+	if s.installment != nil {
+		return fmt.Errorf("installment plans have a fixed payment")
+	}
+	s.promotion = &promotion
+	return nil
+}
+
+// checkPromotionCurrency rejects a price in another currency than the
+// running promotion
+func (s *Subscription) checkPromotionCurrency(currency string) error {
+	if s.promotion != nil && s.promotion.Price.Currency != currency {
+		return fmt.Errorf("cannot change the currency while a promotion in %s is running", s.promotion.Price.Currency)
+	}
+	return nil
+}
+
+// ClearPromotion ends the promotion so the regular price is charged
+func (s *Subscription) ClearPromotion() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.promotion = nil
+}
+
+// chargePromotion returns the promotional price when it applies to the
+// payment due on the date and counts the payment against the promotion.
+// A promotion that has run out is removed.
+func (s *Subscription) chargePromotion(date time.Time) (Money, bool) {
+	if s.promotion == nil {
+		return Money{}, false
+	}
+	if !s.promotion.appliesTo(date) {
+		s.promotion = nil
+		return Money{}, false
+	}
+
+	price := s.promotion.Price
+	if s.promotion.Payments > 0 {
+		s.promotion.Payments--
+		if s.promotion.Payments == 0 {
+			s.promotion = nil
+		}
+	}
+	return price, true
+}
+
+// PromotionEnd returns the business day in the calendar of the first payment
+// charged the regular price after the promotion, and that price. It reports
+// false when there is no promotion or no regular payment follows it.
+func (s *Subscription) PromotionEnd(calendar *HolidayCalendar) (time.Time, Money, bool) {
+	if _, ok := s.Promotion(); !ok {
+		return time.Time{}, Money{}, false
+	}
+
+	// Walk a copy of the schedule so the subscription itself is untouched
+	sim := s.Clone()
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	sim.state = StateActive
+	for i := 0; i < maxPromotionWalk && !sim.hasEnded(); i++ {
+		if sim.promotion == nil || !sim.promotion.appliesTo(sim.nextPaymentDate) {
+			return calendar.Adjust(sim.nextPaymentDate, sim.rollConvention), sim.priceOn(sim.nextPaymentDate), true
+		}
+		if err := sim.processPayment(PaymentSourceAuto, sim.nextPaymentDate); err != nil {
+			break
+		}
+	}
+	return time.Time{}, Money{}, false
+}
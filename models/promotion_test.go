@@ -0,0 +1,65 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPromotionCurrencyFollowsPrices(t *testing.T) {
+	effective := Today(time.Now(), time.UTC).AddDate(0, 1, 0)
+	promotion := Promotion{Price: usd(199), Payments: 2}
+
+	sub := newTestSubscription(t, usd(999), 5, OpenEnded)
+	if err := sub.SetPromotion(promotion); err != nil {
+		t.Fatal(err)
+	}
+	if err := sub.SchedulePriceChange(Money{Amount: 999, Currency: "EUR"}, effective); err == nil {
+		t.Error("SchedulePriceChange accepted a EUR price during a USD promotion")
+	}
+	if err := sub.SetCost(Money{Amount: 999, Currency: "EUR"}); err == nil {
+		t.Error("SetCost accepted a EUR price during a USD promotion")
+	}
+	restore(t, sub)
+
+	sub = newTestSubscription(t, usd(999), 5, OpenEnded)
+	if err := sub.SchedulePriceChange(Money{Amount: 999, Currency: "EUR"}, effective); err != nil {
+		t.Fatal(err)
+	}
+	if err := sub.SetPromotion(promotion); err == nil {
+		t.Error("SetPromotion accepted a USD promotion with a EUR price scheduled")
+	}
+}
+
+func TestPromotionCharges(t *testing.T) {
+	tests := []struct {
+		name      string
+		promotion Promotion
+		want      []int64
+	}{
+		{"payments", Promotion{Price: usd(199), Payments: 2}, []int64{199, 199, 999, 999}},
+		{"end date", Promotion{Price: usd(99), EndDate: Today(time.Now(), time.UTC).AddDate(0, 1, 10)}, []int64{99, 99, 999, 999}},
+		{"whichever first", Promotion{Price: usd(99), Payments: 1, EndDate: Today(time.Now(), time.UTC).AddDate(0, 3, 0)}, []int64{99, 999, 999, 999}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub := newTestSubscription(t, usd(999), 5, OpenEnded)
+			if err := sub.SetPromotion(tt.promotion); err != nil {
+				t.Fatal(err)
+			}
+			for i, want := range tt.want {
+				if got := sub.NextPaymentAmount(); got.Amount != want {
+					t.Errorf("payment %d expected at %s, want %d", i+1, got, want)
+				}
+				if err := sub.ProcessPayment(nil); err != nil {
+					t.Fatal(err)
+				}
+				if got := sub.Payments()[i].Amount.Amount; got != want {
+					t.Errorf("payment %d charged %d, want %d", i+1, got, want)
+				}
+			}
+			if _, ok := sub.Promotion(); ok {
+				t.Error("promotion still running after it ran out")
+			}
+		})
+	}
+}
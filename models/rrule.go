@@ -0,0 +1,479 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxEmptyPeriods bounds how many consecutive periods without an occurrence
+// are searched before a rule is considered to never match again, e.g. for
+// BYMONTH=2;BYMONTHDAY=30
+const maxEmptyPeriods = 1000
+
+var rruleWeekdays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// WeekdayNum is a BYDAY entry: a weekday with an optional ordinal, e.g. -1FR
+// for the last Friday. An ordinal of 0 means every such weekday.
+type WeekdayNum struct {
+	Ordinal int
+	Day     time.Weekday
+}
+
+// RRule is a parsed RFC 5545 recurrence rule. The supported parts are FREQ,
+// INTERVAL, BYDAY, BYMONTHDAY, BYMONTH, BYSETPOS, COUNT, UNTIL and WKST.
+type RRule struct {
+	Freq       FrequencyUnit
+	Interval   int
+	ByDay      []WeekdayNum
+	ByMonthDay []int
+	ByMonth    []time.Month
+	BySetPos   []int
+	Count      int
+	Until      time.Time
+	WeekStart  time.Weekday
+	untilUTC   bool
+	untilDate  bool
+	raw        string
+}
+
+// TrimRRulePrefix removes surrounding space and a leading "RRULE:" property
+// name, which RFC 5545 treats as case-insensitive
+func TrimRRulePrefix(value string) string {
+	value = strings.TrimSpace(value)
+	if len(value) >= len("RRULE:") && strings.EqualFold(value[:len("RRULE:")], "RRULE:") {
+		return value[len("RRULE:"):]
+	}
+	return value
+}
+
+// ParseRRule parses a recurrence rule such as "FREQ=MONTHLY;BYMONTHDAY=1,15".
+// A leading "RRULE:" prefix is accepted.
+func ParseRRule(value string) (*RRule, error) {
+	raw := TrimRRulePrefix(value)
+	rule := &RRule{Interval: 1, WeekStart: time.Monday, raw: raw}
+
+	for _, part := range strings.Split(raw, ";") {
+		if part == "" {
+			continue
+		}
+		key, val, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid rule part '%s'", part)
+		}
+
+		var err error
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			rule.Freq, err = parseRRuleFreq(val)
+		case "INTERVAL":
+			rule.Interval, err = strconv.Atoi(val)
+			if err == nil && rule.Interval <= 0 {
+				err = fmt.Errorf("INTERVAL must be greater than 0")
+			}
+		case "BYDAY":
+			rule.ByDay, err = parseByDay(val)
+		case "BYMONTHDAY":
+			rule.ByMonthDay, err = parseIntList(val, -31, 31)
+		case "BYMONTH":
+			var months []int
+			months, err = parseIntList(val, 1, 12)
+			for _, m := range months {
+				rule.ByMonth = append(rule.ByMonth, time.Month(m))
+			}
+		case "BYSETPOS":
+			rule.BySetPos, err = parseIntList(val, -366, 366)
+		case "COUNT":
+			rule.Count, err = strconv.Atoi(val)
+			if err == nil && rule.Count <= 0 {
+				err = fmt.Errorf("COUNT must be greater than 0")
+			}
+		case "UNTIL":
+			rule.Until, err = parseUntil(val)
+			rule.untilUTC = strings.HasSuffix(strings.ToUpper(val), "Z")
+			rule.untilDate = len(val) == len("20060102")
+		case "WKST":
+			day, ok := rruleWeekdays[strings.ToUpper(val)]
+			if !ok {
+				err = fmt.Errorf("invalid WKST '%s'", val)
+			}
+			rule.WeekStart = day
+		default:
+			err = fmt.Errorf("unsupported rule part '%s'", key)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid recurrence rule: %v", err)
+		}
+	}
+
+	if rule.Freq == "" {
+		return nil, fmt.Errorf("invalid recurrence rule: FREQ is required")
+	}
+	if rule.Count > 0 && !rule.Until.IsZero() {
+		return nil, fmt.Errorf("invalid recurrence rule: COUNT and UNTIL cannot both be set")
+	}
+	return rule, nil
+}
+
+func parseRRuleFreq(value string) (FrequencyUnit, error) {
+	switch strings.ToUpper(value) {
+	case "DAILY":
+		return UnitDay, nil
+	case "WEEKLY":
+		return UnitWeek, nil
+	case "MONTHLY":
+		return UnitMonth, nil
+	case "YEARLY":
+		return UnitYear, nil
+	}
+	return "", fmt.Errorf("unsupported FREQ '%s'", value)
+}
+
+func parseByDay(value string) ([]WeekdayNum, error) {
+	var days []WeekdayNum
+	for _, item := range strings.Split(value, ",") {
+		item = strings.ToUpper(strings.TrimSpace(item))
+		if len(item) < 2 {
+			return nil, fmt.Errorf("invalid BYDAY '%s'", item)
+		}
+		day, ok := rruleWeekdays[item[len(item)-2:]]
+		if !ok {
+			return nil, fmt.Errorf("invalid BYDAY '%s'", item)
+		}
+		ordinal := 0
+		if prefix := item[:len(item)-2]; prefix != "" {
+			n, err := strconv.Atoi(prefix)
+			if err != nil || n == 0 || n < -53 || n > 53 {
+				return nil, fmt.Errorf("invalid BYDAY '%s'", item)
+			}
+			ordinal = n
+		}
+		days = append(days, WeekdayNum{Ordinal: ordinal, Day: day})
+	}
+	return days, nil
+}
+
+func parseIntList(value string, min, max int) ([]int, error) {
+	var values []int
+	for _, item := range strings.Split(value, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(item))
+		if err != nil || n == 0 || n < min || n > max {
+			return nil, fmt.Errorf("invalid value '%s'", item)
+		}
+		values = append(values, n)
+	}
+	return values, nil
+}
+
+func parseUntil(value string) (time.Time, error) {
+	for _, layout := range []string{"20060102T150405Z", "20060102T150405", "20060102"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid UNTIL '%s'", value)
+}
+
+// String returns the rule as it was written
+func (r *RRule) String() string {
+	return r.raw
+}
+
+// After returns the first occurrence strictly after t for a rule starting at
+// start. The boolean is false when the rule has no further occurrences
+// because of COUNT or UNTIL, or because it never matches again.
+func (r *RRule) After(start, t time.Time) (time.Time, bool) {
+	return r.next(start, t, func(occurrence time.Time) bool { return occurrence.After(t) })
+}
+
+// From returns the first occurrence at or after t for a rule starting at start
+func (r *RRule) From(start, t time.Time) (time.Time, bool) {
+	return r.next(start, t, func(occurrence time.Time) bool { return !occurrence.Before(t) })
+}
+
+func (r *RRule) next(start, t time.Time, accept func(time.Time) bool) (time.Time, bool) {
+	// Without COUNT there is no need to walk every period since the start,
+	// so begin with the period just before the one containing t
+	first := 0
+	if r.Count == 0 {
+		first = r.periodsBetween(start, t)/r.Interval - 1
+		if first < 0 {
+			first = 0
+		}
+	}
+
+	count := 0
+	empty := 0
+	for period := first; empty < maxEmptyPeriods; period++ {
+		occurrences := r.expandPeriod(start, period*r.Interval)
+		if len(occurrences) == 0 {
+			empty++
+			continue
+		}
+		empty = 0
+
+		for _, occurrence := range occurrences {
+			if occurrence.Before(start) {
+				continue
+			}
+			if !r.Until.IsZero() && occurrence.After(r.untilIn(start.Location())) {
+				return time.Time{}, false
+			}
+			count++
+			if r.Count > 0 && count > r.Count {
+				return time.Time{}, false
+			}
+			if accept(occurrence) {
+				return occurrence, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// periodsBetween returns the number of whole frequency units from the period
+// containing start to the period containing t
+func (r *RRule) periodsBetween(start, t time.Time) int {
+	if !t.After(start) {
+		return 0
+	}
+	switch r.Freq {
+	case UnitDay:
+		return int(dateOf(t).Sub(dateOf(start)).Hours() / 24)
+	case UnitWeek:
+		return int(dateOf(t).Sub(dateOf(start)).Hours()/24) / 7
+	case UnitMonth:
+		return (t.Year()-start.Year())*12 + int(t.Month()) - int(start.Month())
+	case UnitYear:
+		return t.Year() - start.Year()
+	}
+	return 0
+}
+
+// untilIn interprets a floating UNTIL (no "Z" suffix) in the schedule's location
+func (r *RRule) untilIn(loc *time.Location) time.Time {
+	u := r.Until
+	if r.untilUTC {
+		return u
+	}
+	if r.untilDate {
+		// A date-only UNTIL includes the whole day
+		return time.Date(u.Year(), u.Month(), u.Day(), 23, 59, 59, 0, loc)
+	}
+	return time.Date(u.Year(), u.Month(), u.Day(), u.Hour(), u.Minute(), u.Second(), 0, loc)
+}
+
+// expandPeriod returns the sorted occurrences in the period that is offset
+// units of the rule's frequency after the period containing start
+func (r *RRule) expandPeriod(start time.Time, offset int) []time.Time {
+	var days []time.Time
+	switch r.Freq {
+	case UnitDay:
+		day := dateOf(start).AddDate(0, 0, offset)
+		if r.matchesMonth(day) && r.matchesMonthDay(day) && r.matchesWeekday(day) {
+			days = append(days, day)
+		}
+	case UnitWeek:
+		weekStart := dateOf(start)
+		for weekStart.Weekday() != r.WeekStart {
+			weekStart = weekStart.AddDate(0, 0, -1)
+		}
+		weekStart = weekStart.AddDate(0, 0, 7*offset)
+		for i := 0; i < 7; i++ {
+			day := weekStart.AddDate(0, 0, i)
+			if !r.matchesMonth(day) {
+				continue
+			}
+			if len(r.ByDay) == 0 && day.Weekday() != start.Weekday() {
+				continue
+			}
+			if len(r.ByDay) > 0 && !r.matchesWeekday(day) {
+				continue
+			}
+			days = append(days, day)
+		}
+	case UnitMonth:
+		first := time.Date(start.Year(), start.Month()+time.Month(offset), 1, 0, 0, 0, 0, start.Location())
+		if r.matchesMonth(first) {
+			days = r.expandMonth(first, start)
+		}
+	case UnitYear:
+		year := start.Year() + offset
+		days = r.expandYear(year, start)
+	}
+
+	days = r.applySetPos(days)
+
+	occurrences := make([]time.Time, len(days))
+	for i, day := range days {
+		occurrences[i] = time.Date(day.Year(), day.Month(), day.Day(), start.Hour(), start.Minute(), start.Second(), start.Nanosecond(), start.Location())
+	}
+	return occurrences
+}
+
+// expandMonth returns the matching days of the month starting at first
+func (r *RRule) expandMonth(first time.Time, start time.Time) []time.Time {
+	lastDay := daysIn(first.Year(), first.Month(), first.Location())
+
+	if len(r.ByDay) == 0 && len(r.ByMonthDay) == 0 {
+		// Default to the start's day of month, skipping months that lack it
+		if start.Day() > lastDay {
+			return nil
+		}
+		return []time.Time{first.AddDate(0, 0, start.Day()-1)}
+	}
+
+	var days []time.Time
+	for d := 1; d <= lastDay; d++ {
+		day := first.AddDate(0, 0, d-1)
+		if len(r.ByMonthDay) > 0 && !r.matchesMonthDay(day) {
+			continue
+		}
+		if len(r.ByDay) > 0 && !r.matchesWeekdayInRange(day, first, lastDay) {
+			continue
+		}
+		days = append(days, day)
+	}
+	return days
+}
+
+// expandYear returns the matching days of the year
+func (r *RRule) expandYear(year int, start time.Time) []time.Time {
+	loc := start.Location()
+
+	if len(r.ByMonth) > 0 || len(r.ByMonthDay) > 0 {
+		months := r.ByMonth
+		if len(months) == 0 {
+			if len(r.ByDay) == 0 && len(r.ByMonthDay) == 0 {
+				months = []time.Month{start.Month()}
+			} else {
+				months = []time.Month{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}
+			}
+		}
+		sorted := append([]time.Month(nil), months...)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+		var days []time.Time
+		for _, month := range sorted {
+			days = append(days, r.expandMonth(time.Date(year, month, 1, 0, 0, 0, 0, loc), start)...)
+		}
+		return days
+	}
+
+	if len(r.ByDay) > 0 {
+		// Ordinals count within the whole year when no BYMONTH is given
+		first := time.Date(year, 1, 1, 0, 0, 0, 0, loc)
+		yearDays := time.Date(year, 12, 31, 0, 0, 0, 0, loc).YearDay()
+		var days []time.Time
+		for d := 1; d <= yearDays; d++ {
+			day := first.AddDate(0, 0, d-1)
+			if r.matchesWeekdayInRange(day, first, yearDays) {
+				days = append(days, day)
+			}
+		}
+		return days
+	}
+
+	// Default to the start's month and day, skipping years that lack it
+	day := time.Date(year, start.Month(), start.Day(), 0, 0, 0, 0, loc)
+	if day.Month() != start.Month() {
+		return nil
+	}
+	return []time.Time{day}
+}
+
+func (r *RRule) applySetPos(days []time.Time) []time.Time {
+	if len(r.BySetPos) == 0 || len(days) == 0 {
+		return days
+	}
+
+	var selected []time.Time
+	seen := make(map[int]bool)
+	for _, pos := range r.BySetPos {
+		index := pos - 1
+		if pos < 0 {
+			index = len(days) + pos
+		}
+		if index < 0 || index >= len(days) || seen[index] {
+			continue
+		}
+		seen[index] = true
+		selected = append(selected, days[index])
+	}
+	sort.Slice(selected, func(i, j int) bool { return selected[i].Before(selected[j]) })
+	return selected
+}
+
+func (r *RRule) matchesMonth(day time.Time) bool {
+	if len(r.ByMonth) == 0 {
+		return true
+	}
+	for _, month := range r.ByMonth {
+		if day.Month() == month {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *RRule) matchesMonthDay(day time.Time) bool {
+	if len(r.ByMonthDay) == 0 {
+		return true
+	}
+	lastDay := daysIn(day.Year(), day.Month(), day.Location())
+	for _, monthDay := range r.ByMonthDay {
+		if monthDay == day.Day() || (monthDay < 0 && lastDay+monthDay+1 == day.Day()) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesWeekday checks BYDAY ignoring ordinals, as used by DAILY and WEEKLY rules
+func (r *RRule) matchesWeekday(day time.Time) bool {
+	if len(r.ByDay) == 0 {
+		return true
+	}
+	for _, weekday := range r.ByDay {
+		if weekday.Day == day.Weekday() {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesWeekdayInRange checks BYDAY where ordinals count occurrences of the
+// weekday within the range of length days starting at first
+func (r *RRule) matchesWeekdayInRange(day time.Time, first time.Time, length int) bool {
+	index := int(dateOf(day).Sub(dateOf(first)).Hours()/24+0.5) + 1
+	for _, weekday := range r.ByDay {
+		if weekday.Day != day.Weekday() {
+			continue
+		}
+		if weekday.Ordinal == 0 {
+			return true
+		}
+		nth := (index-1)/7 + 1
+		nthFromEnd := -((length-index)/7 + 1)
+		if weekday.Ordinal == nth || weekday.Ordinal == nthFromEnd {
+			return true
+		}
+	}
+	return false
+}
+
+// dateOf returns midnight of the date in its own location
+func dateOf(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,161 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// occurrences returns up to n occurrences of the rule from start on
+func occurrences(t *testing.T, rule string, start time.Time, n int) []time.Time {
+	t.Helper()
+	parsed, err := ParseRRule(rule)
+	if err != nil {
+		t.Fatalf("ParseRRule(%q): %v", rule, err)
+	}
+	var dates []time.Time
+	next, ok := parsed.From(start, start)
+	for ok && len(dates) < n {
+		dates = append(dates, next)
+		next, ok = parsed.After(start, next)
+	}
+	return dates
+}
+
+func TestRRuleOccurrences(t *testing.T) {
+	tests := []struct {
+		name  string
+		rule  string
+		start time.Time
+		want  []time.Time
+		// final is set when the rule has no occurrences after want
+		final bool
+	}{
+		{
+			name:  "month days",
+			rule:  "FREQ=MONTHLY;BYMONTHDAY=1,15",
+			start: date(2027, 1, 1),
+			want:  []time.Time{date(2027, 1, 1), date(2027, 1, 15), date(2027, 2, 1), date(2027, 2, 15)},
+		},
+		{
+			name:  "lowercase prefix",
+			rule:  "rrule:FREQ=WEEKLY;BYDAY=MO,FR",
+			start: date(2027, 1, 4),
+			want:  []time.Time{date(2027, 1, 4), date(2027, 1, 8), date(2027, 1, 11), date(2027, 1, 15)},
+		},
+		{
+			name:  "last friday",
+			rule:  "RRULE:FREQ=MONTHLY;BYDAY=-1FR",
+			start: date(2027, 1, 1),
+			want:  []time.Time{date(2027, 1, 29), date(2027, 2, 26), date(2027, 3, 26), date(2027, 4, 30)},
+		},
+		{
+			name:  "last weekday",
+			rule:  "FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1",
+			start: date(2027, 1, 1),
+			want:  []time.Time{date(2027, 1, 29), date(2027, 2, 26), date(2027, 3, 31), date(2027, 4, 30)},
+		},
+		{
+			name:  "leap day every other year",
+			rule:  "FREQ=YEARLY;INTERVAL=2;BYMONTH=2;BYMONTHDAY=29",
+			start: date(2028, 1, 1),
+			want:  []time.Time{date(2028, 2, 29), date(2032, 2, 29), date(2036, 2, 29)},
+		},
+		{
+			name:  "count",
+			rule:  "FREQ=DAILY;INTERVAL=10;COUNT=3",
+			start: date(2027, 1, 1),
+			want:  []time.Time{date(2027, 1, 1), date(2027, 1, 11), date(2027, 1, 21)},
+			final: true,
+		},
+		{
+			name:  "until",
+			rule:  "FREQ=WEEKLY;UNTIL=20270115",
+			start: date(2027, 1, 1),
+			want:  []time.Time{date(2027, 1, 1), date(2027, 1, 8), date(2027, 1, 15)},
+			final: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := occurrences(t, tt.rule, tt.start, len(tt.want)+1)
+			if !tt.final && len(got) > len(tt.want) {
+				got = got[:len(tt.want)]
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if !got[i].Equal(tt.want[i]) {
+					t.Errorf("occurrence %d = %s, want %s", i, got[i].Format("2006-01-02"), tt.want[i].Format("2006-01-02"))
+				}
+			}
+		})
+	}
+}
+
+func TestParseRRuleErrors(t *testing.T) {
+	for _, rule := range []string{
+		"",
+		"FREQ=HOURLY",
+		"FREQ=MONTHLY;INTERVAL=0",
+		"FREQ=MONTHLY;BYMONTHDAY=32",
+		"FREQ=WEEKLY;BYDAY=XX",
+		"FREQ=MONTHLY;COUNT=2;UNTIL=20270101",
+		"FREQ",
+	} {
+		if _, err := ParseRRule(rule); err == nil {
+			t.Errorf("ParseRRule(%q) succeeded, want an error", rule)
+		}
+	}
+}
+
+func TestTrimRRulePrefix(t *testing.T) {
+	tests := map[string]string{
+		"RRULE:FREQ=DAILY":   "FREQ=DAILY",
+		"rrule:FREQ=DAILY":   "FREQ=DAILY",
+		" RRule:FREQ=DAILY ": "FREQ=DAILY",
+		"FREQ=DAILY":         "FREQ=DAILY",
+		"RRULE":              "RRULE",
+	}
+	for value, want := range tests {
+		if got := TrimRRulePrefix(value); got != want {
+			t.Errorf("TrimRRulePrefix(%q) = %q, want %q", value, got, want)
+		}
+	}
+}
+
+func TestSetRecurrenceStartsOnAnOccurrence(t *testing.T) {
+	sub := newTestSubscription(t, usd(999), 1, OpenEnded)
+	rule, err := ParseRRule("FREQ=MONTHLY;BYMONTHDAY=15;COUNT=3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	start, _ := rule.From(sub.NextPaymentDate(), sub.NextPaymentDate())
+
+	// The start is the first of the COUNT occurrences, so it must be one
+	if err := sub.SetRecurrence(rule.String(), start.AddDate(0, 0, 1)); err == nil {
+		t.Error("SetRecurrence accepted a start the rule does not produce")
+	}
+	if err := sub.SetRecurrence(rule.String(), start); err != nil {
+		t.Fatal(err)
+	}
+	if !sub.NextPaymentDate().Equal(start) {
+		t.Errorf("next payment on %s, want %s", sub.NextPaymentDate().Format("2006-01-02"), start.Format("2006-01-02"))
+	}
+
+	payments := 0
+	for !sub.HasEnded() {
+		if err := sub.ProcessPayment(nil); err != nil {
+			t.Fatal(err)
+		}
+		payments++
+	}
+	if payments != 3 {
+		t.Errorf("%d payments, want 3 including the start", payments)
+	}
+}
@@ -0,0 +1,111 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Settings holds user preferences that apply across all subscriptions
+type Settings struct {
+	BaseCurrency   string
+	ExchangeRates  ExchangeRates
+	PaymentMethods []PaymentMethod
+	CostPeriod     Period
+	Budgets        []Budget
+	Holidays       []Holiday
+	// TimeZone is the IANA name of the zone payment dates are calendar dates
+	// in; empty means the system's local time zone
+	TimeZone string
+}
+
+// DefaultSettings returns the settings used before the user configures anything
+func DefaultSettings() Settings {
+	return Settings{
+		BaseCurrency: DefaultCurrency,
+		CostPeriod:   DefaultPeriod,
+		ExchangeRates: ExchangeRates{
+			Base:  DefaultCurrency,
+			Rates: make(map[string]float64),
+		},
+	}
+}
+
+func (s Settings) Validate() error {
+	var validationErrors []string
+
+	if !ValidCurrency(s.BaseCurrency) {
+		validationErrors = append(validationErrors, fmt.Sprintf("unsupported base currency '%s'", s.BaseCurrency))
+	}
+	if s.ExchangeRates.Base != s.BaseCurrency {
+		validationErrors = append(validationErrors, "exchange rates must be expressed in the base currency")
+	}
+	if err := s.ExchangeRates.Validate(); err != nil {
+		validationErrors = append(validationErrors, err.Error())
+	}
+	if err := s.CostPeriod.Validate(); err != nil {
+		validationErrors = append(validationErrors, err.Error())
+	}
+	seenCategories := make(map[string]bool, len(s.Budgets))
+	for _, budget := range s.Budgets {
+		if err := budget.Validate(); err != nil {
+			validationErrors = append(validationErrors, err.Error())
+		}
+		key := strings.ToLower(budget.Category)
+		if seenCategories[key] {
+			validationErrors = append(validationErrors, fmt.Sprintf("more than one budget for %s", budget.Label()))
+		}
+		seenCategories[key] = true
+	}
+	if err := ValidateTimeZone(s.TimeZone); err != nil {
+		validationErrors = append(validationErrors, err.Error())
+	}
+	for _, holiday := range s.Holidays {
+		if err := holiday.Validate(); err != nil {
+			validationErrors = append(validationErrors, err.Error())
+		}
+	}
+	seenIDs := make(map[string]bool, len(s.PaymentMethods))
+	for _, method := range s.PaymentMethods {
+		if err := method.Validate(); err != nil {
+			validationErrors = append(validationErrors, fmt.Sprintf("payment method '%s': %v", method.Label, err))
+		}
+		if seenIDs[method.ID] {
+			validationErrors = append(validationErrors, fmt.Sprintf("duplicate payment method ID '%s'", method.ID))
+		}
+		seenIDs[method.ID] = true
+	}
+
+	if len(validationErrors) > 0 {
+		return &ValidationError{Errors: validationErrors}
+	}
+	return nil
+}
+
+// PaymentMethod returns the payment method with the given ID
+func (s Settings) PaymentMethod(id string) (PaymentMethod, bool) {
+	for _, method := range s.PaymentMethods {
+		if method.ID == id {
+			return method, true
+		}
+	}
+	return PaymentMethod{}, false
+}
+
+// Calendar returns the holiday calendar payment dates are adjusted against
+func (s Settings) Calendar() *HolidayCalendar {
+	return NewHolidayCalendar(s.Holidays)
+}
+
+// Location returns the time zone payment dates are calendar dates in
+func (s Settings) Location() *time.Location {
+	if s.TimeZone == "" {
+		return time.Local
+	}
+	loc, err := time.LoadLocation(s.TimeZone)
+	if err != nil {
+		// Settings are validated when saved or loaded
+		return time.Local
+	}
+	return loc
+}
@@ -0,0 +1,191 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// OwnerName labels the part of a shared subscription's cost that is not
+// assigned to any participant
+const OwnerName = "You"
+
+// ShareKind is how a participant's share of the cost is expressed
+type ShareKind string
+
+// Valid share kinds
+const (
+	SharePercent ShareKind = "percent"
+	ShareFixed   ShareKind = "fixed"
+)
+
+// Participant is a person who pays part of a shared subscription, either a
+// percentage of the full cost or a fixed amount per payment
+type Participant struct {
+	Name    string
+	Kind    ShareKind
+	Percent float64
+	Amount  Money
+}
+
+func (p Participant) Validate() error {
+	if strings.TrimSpace(p.Name) == "" {
+		return fmt.Errorf("participant name cannot be empty")
+	}
+	if strings.EqualFold(p.Name, OwnerName) {
+		return fmt.Errorf("participant name '%s' is reserved for your own share", OwnerName)
+	}
+	switch p.Kind {
+	case SharePercent:
+		// Written so that NaN is rejected too
+		if !(p.Percent > 0 && p.Percent <= 100) {
+			return fmt.Errorf("share of %s must be between 0 and 100 percent", p.Name)
+		}
+	case ShareFixed:
+		if !p.Amount.IsPositive() || !ValidCurrency(p.Amount.Currency) {
+			return fmt.Errorf("share of %s must be a positive amount", p.Name)
+		}
+	default:
+		return fmt.Errorf("invalid share kind '%s'", p.Kind)
+	}
+	return nil
+}
+
+// String describes the share, e.g. "Alex 25%" or "Sam $4.00"
+func (p Participant) String() string {
+	if p.Kind == SharePercent {
+		return fmt.Sprintf("%s %s%%", p.Name, formatPercent(p.Percent))
+	}
+	return fmt.Sprintf("%s %s", p.Name, p.Amount)
+}
+
+func formatPercent(percent float64) string {
+	return strings.TrimSuffix(strings.TrimRight(fmt.Sprintf("%.2f", percent), "0"), ".")
+}
+
+// Share is the amount one person pays of a single payment
+type Share struct {
+	Name   string
+	Amount Money
+}
+
+// Participants returns the people sharing the subscription
+func (s *Subscription) Participants() []Participant {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]Participant(nil), s.participants...)
+}
+
+// IsShared reports whether anyone shares the cost of the subscription
+func (s *Subscription) IsShared() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.participants) > 0
+}
+
+// SetParticipants replaces the participants. Together their shares may not
+// exceed the current cost; whatever is left is your own share. Fixed shares
+// must be in the currency of the cost and of every scheduled price. An empty
+// list makes the subscription unshared.
+func (s *Subscription) SetParticipants(participants []Participant) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := validateParticipants(participants, s.cost.Currency); err != nil {
+		return err
+	}
+	for _, change := range s.scheduledPrices {
+		if err := validateParticipants(participants, change.Cost.Currency); err != nil {
+			return fmt.Errorf("%v from the price change on %s", err, change.EffectiveDate.Format("2006-01-02"))
+		}
+	}
+	if shares := splitCost(participants, s.cost); shares[len(shares)-1].Amount.Amount < 0 {
+		return fmt.Errorf("shares add up to more than the cost of %s", s.cost)
+	}
+	s.participants = append([]Participant(nil), participants...)
+	return nil
+}
+
+func validateParticipants(participants []Participant, currency string) error {
+	seen := make(map[string]bool, len(participants))
+	for _, participant := range participants {
+		if err := participant.Validate(); err != nil {
+			return err
+		}
+		key := strings.ToLower(participant.Name)
+		if seen[key] {
+			return fmt.Errorf("participant '%s' is listed more than once", participant.Name)
+		}
+		seen[key] = true
+		if participant.Kind == ShareFixed && participant.Amount.Currency != currency {
+			return fmt.Errorf("share of %s must be in %s", participant.Name, currency)
+		}
+	}
+	return nil
+}
+
+// Split divides the current cost between the participants. Your own share
+// comes last under OwnerName; it is negative when the shares add up to more
+// than a lowered price.
+func (s *Subscription) Split() []Share {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return splitCost(s.participants, s.cost)
+}
+
+// splitCost divides the cost, rounding percentage shares to the minor unit
+// and leaving any rounding difference in your own share
+func splitCost(participants []Participant, cost Money) []Share {
+	shares := make([]Share, 0, len(participants)+1)
+	remaining := cost.Amount
+	for _, participant := range participants {
+		amount := participant.Amount
+		if participant.Kind == SharePercent {
+			amount = cost.Scale(participant.Percent / 100)
+		}
+		shares = append(shares, Share{Name: participant.Name, Amount: amount})
+		remaining -= amount.Amount
+	}
+	return append(shares, Share{Name: OwnerName, Amount: Money{Amount: remaining, Currency: cost.Currency}})
+}
+
+// ParseParticipant parses a share written as "Name=25%" or "Name=4.99", with
+// fixed amounts in the given currency
+func ParseParticipant(value string, currency string) (Participant, error) {
+	name, share, ok := strings.Cut(value, "=")
+	if !ok {
+		return Participant{}, fmt.Errorf("expected Name=percent%% or Name=amount, got '%s'", value)
+	}
+	name, share = strings.TrimSpace(name), strings.TrimSpace(share)
+
+	participant := Participant{Name: name}
+	if percentStr, isPercent := strings.CutSuffix(share, "%"); isPercent {
+		// ParseFloat rejects anything after the number, unlike Sscanf
+		percent, err := strconv.ParseFloat(strings.TrimSpace(percentStr), 64)
+		if err != nil {
+			return Participant{}, fmt.Errorf("invalid percentage '%s' for %s", share, name)
+		}
+		participant.Kind = SharePercent
+		participant.Percent = percent
+	} else {
+		amount, err := ParseMoney(share, currency)
+		if err != nil {
+			return Participant{}, fmt.Errorf("invalid amount '%s' for %s", share, name)
+		}
+		participant.Kind = ShareFixed
+		participant.Amount = amount
+	}
+
+	if err := participant.Validate(); err != nil {
+		return Participant{}, err
+	}
+	return participant, nil
+}
+
+// FormatParticipant writes a participant in the form read by ParseParticipant
+func FormatParticipant(participant Participant) string {
+	if participant.Kind == SharePercent {
+		return fmt.Sprintf("%s=%s%%", participant.Name, formatPercent(participant.Percent))
+	}
+	return fmt.Sprintf("%s=%s", participant.Name, participant.Amount.FormatAmount())
+}
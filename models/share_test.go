@@ -0,0 +1,80 @@
+package models
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSplitCost(t *testing.T) {
+	tests := []struct {
+		name         string
+		participants []string
+		cost         Money
+		want         []int64
+	}{
+		{"unshared", nil, usd(1599), []int64{1599}},
+		{"percent", []string{"Alex=25%"}, usd(2000), []int64{500, 1500}},
+		{"rounding stays with you", []string{"Alex=33.33%", "Sam=33.33%"}, usd(1000), []int64{333, 333, 334}},
+		{"fixed", []string{"Sam=4.99"}, usd(1599), []int64{499, 1100}},
+		{"mixed", []string{"Alex=50%", "Sam=2.50"}, usd(1001), []int64{501, 250, 250}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var participants []Participant
+			for _, value := range tt.participants {
+				participant, err := ParseParticipant(value, tt.cost.Currency)
+				if err != nil {
+					t.Fatal(err)
+				}
+				participants = append(participants, participant)
+			}
+			shares := splitCost(participants, tt.cost)
+			if len(shares) != len(tt.want) {
+				t.Fatalf("got %d shares, want %d", len(shares), len(tt.want))
+			}
+			for i, share := range shares {
+				if share.Amount.Amount != tt.want[i] || share.Amount.Currency != tt.cost.Currency {
+					t.Errorf("share of %s = %s, want %d minor units", share.Name, share.Amount, tt.want[i])
+				}
+			}
+			if last := shares[len(shares)-1].Name; last != OwnerName {
+				t.Errorf("last share belongs to %s, want %s", last, OwnerName)
+			}
+		})
+	}
+}
+
+func TestParseParticipantErrors(t *testing.T) {
+	for _, value := range []string{"Alex", "Alex=abc%", "Alex=150%", "Alex=1.999", "=25%", "You=25%",
+		"Alex=25abc%", "Alex=25 5%", "Alex=%", "Alex=NaN%", "Alex=-5%", "Alex=25%%"} {
+		if _, err := ParseParticipant(value, "USD"); err == nil {
+			t.Errorf("ParseParticipant(%q) succeeded, want an error", value)
+		}
+	}
+}
+
+func TestFixedSharesFollowScheduledCurrency(t *testing.T) {
+	effective := Today(time.Now(), time.UTC).AddDate(0, 1, 0)
+	fixed := []Participant{{Name: "Sam", Kind: ShareFixed, Amount: usd(400)}}
+
+	// Shares first, then a price change in another currency
+	sub := newTestSubscription(t, usd(1000), 5, OpenEnded)
+	if err := sub.SetParticipants(fixed); err != nil {
+		t.Fatal(err)
+	}
+	err := sub.SchedulePriceChange(Money{Amount: 1000, Currency: "EUR"}, effective)
+	if err == nil || !strings.Contains(err.Error(), "share of Sam must be in EUR") {
+		t.Errorf("SchedulePriceChange = %v, want the share currency error", err)
+	}
+
+	// A price change in another currency first, then shares
+	sub = newTestSubscription(t, usd(1000), 5, OpenEnded)
+	if err := sub.SchedulePriceChange(Money{Amount: 1000, Currency: "EUR"}, effective); err != nil {
+		t.Fatal(err)
+	}
+	if err := sub.SetParticipants(fixed); err == nil {
+		t.Error("SetParticipants accepted USD shares with a EUR price scheduled")
+	}
+	restore(t, sub)
+}
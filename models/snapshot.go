@@ -0,0 +1,280 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// SubscriptionSnapshot holds every persisted field of a subscription. It is
+// used to move subscriptions in and out of storage without losing state.
+type SubscriptionSnapshot struct {
+	ID                string
+	Name              string
+	Category          string
+	Tags              []string
+	PaymentMethodID   string
+	Participants      []Participant
+	Cost              Money
+	Variable          bool
+	PaymentFrequency  Frequency
+	Recurrence        string
+	RecurrenceStart   time.Time
+	NextPaymentDate   time.Time
+	AnchorDay         int
+	RollConvention    RollConvention
+	RemainingPayments int
+	TotalPayments     int
+	NoticeDays        int
+	Ended             bool
+	State             State
+	ResumeDate        time.Time
+	AccessEndDate     time.Time
+	TrialEndDate      time.Time
+	Payments          []Payment
+	PriceHistory      []PriceChange
+	ScheduledPrices   []PriceChange
+	Installment       *InstallmentPlan
+	Promotion         *Promotion
+}
+
+// Snapshot returns a copy of all persisted fields
+func (s *Subscription) Snapshot() SubscriptionSnapshot {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	recurrence := ""
+	if s.recurrence != nil {
+		recurrence = s.recurrence.String()
+	}
+
+	return SubscriptionSnapshot{
+		ID:                s.id,
+		Name:              s.name,
+		Category:          s.category,
+		Tags:              append([]string(nil), s.tags...),
+		PaymentMethodID:   s.paymentMethodID,
+		Participants:      append([]Participant(nil), s.participants...),
+		Cost:              s.cost,
+		Variable:          s.variable,
+		PaymentFrequency:  s.paymentFrequency,
+		Recurrence:        recurrence,
+		RecurrenceStart:   s.recurrenceStart,
+		NextPaymentDate:   s.nextPaymentDate,
+		AnchorDay:         s.anchorDay,
+		RollConvention:    s.rollConvention,
+		RemainingPayments: s.remainingPayments,
+		TotalPayments:     s.totalPayments,
+		NoticeDays:        s.noticeDays,
+		Ended:             s.ended,
+		State:             s.state,
+		ResumeDate:        s.resumeDate,
+		AccessEndDate:     s.accessEndDate,
+		TrialEndDate:      s.trialEndDate,
+		Payments:          append([]Payment(nil), s.payments...),
+		PriceHistory:      append([]PriceChange(nil), s.priceHistory...),
+		ScheduledPrices:   append([]PriceChange(nil), s.scheduledPrices...),
+		Installment:       copyInstallment(s.installment),
+		Promotion:         copyPromotion(s.promotion),
+	}
+}
+
+// RestoreSubscription rebuilds a subscription from previously persisted data.
+// Unlike NewSubscription it accepts payment dates in the past and keeps the
+// payment progress as stored, only checking that the data is consistent.
+func RestoreSubscription(snap SubscriptionSnapshot) (*Subscription, error) {
+	var validationErrors []string
+
+	if snap.ID == "" {
+		validationErrors = append(validationErrors, "subscription ID cannot be empty")
+	}
+	if snap.Name == "" {
+		validationErrors = append(validationErrors, "subscription name cannot be empty")
+	}
+	if _, err := normalizeTags(snap.Tags); err != nil {
+		validationErrors = append(validationErrors, err.Error())
+	}
+	// Shares are not checked against the cost, which a price change may lower
+	if err := validateParticipants(snap.Participants, snap.Cost.Currency); err != nil {
+		validationErrors = append(validationErrors, err.Error())
+	}
+	if !snap.Cost.IsPositive() {
+		validationErrors = append(validationErrors, "cost must be greater than 0")
+	}
+	if !ValidCurrency(snap.Cost.Currency) {
+		validationErrors = append(validationErrors, fmt.Sprintf("unsupported currency '%s'", snap.Cost.Currency))
+	}
+	if err := snap.PaymentFrequency.Validate(); err != nil {
+		validationErrors = append(validationErrors, err.Error())
+	}
+	if snap.Recurrence != "" {
+		if _, err := ParseRRule(snap.Recurrence); err != nil {
+			validationErrors = append(validationErrors, err.Error())
+		}
+		if snap.RecurrenceStart.IsZero() {
+			validationErrors = append(validationErrors, "recurrence start date is missing")
+		}
+	}
+	if snap.NextPaymentDate.IsZero() {
+		validationErrors = append(validationErrors, "next payment date is missing")
+	}
+	if snap.AnchorDay < 1 || snap.AnchorDay > 31 {
+		validationErrors = append(validationErrors, "billing anchor day must be between 1 and 31")
+	} else if !snap.NextPaymentDate.IsZero() && !anchorMatches(snap.NextPaymentDate, snap.AnchorDay) {
+		validationErrors = append(validationErrors, fmt.Sprintf("billing anchor day %d does not match the next payment date", snap.AnchorDay))
+	}
+	if err := snap.RollConvention.Validate(); err != nil {
+		validationErrors = append(validationErrors, err.Error())
+	}
+	switch snap.State {
+	case StateActive:
+	case StatePaused:
+	case StateCancelled:
+		if snap.AccessEndDate.IsZero() {
+			validationErrors = append(validationErrors, "cancelled subscriptions need an end-of-access date")
+		}
+	default:
+		// Completion follows from the payment counts and is not stored
+		validationErrors = append(validationErrors, fmt.Sprintf("invalid subscription state '%s'", snap.State))
+	}
+	for i, payment := range snap.Payments {
+		if err := payment.Validate(); err != nil {
+			validationErrors = append(validationErrors, fmt.Sprintf("payment %d: %v", i+1, err))
+		}
+	}
+	var lastChange time.Time
+	for i, change := range snap.PriceHistory {
+		if err := change.Validate(); err != nil {
+			validationErrors = append(validationErrors, fmt.Sprintf("price change %d: %v", i+1, err))
+			continue
+		}
+		if !change.Previous.IsPositive() || !ValidCurrency(change.Previous.Currency) {
+			validationErrors = append(validationErrors, fmt.Sprintf("price change %d: previous price is missing", i+1))
+		}
+		if !change.EffectiveDate.After(lastChange) {
+			validationErrors = append(validationErrors, fmt.Sprintf("price change %d: price history must be in date order", i+1))
+		}
+		lastChange = change.EffectiveDate
+	}
+	for i, change := range snap.ScheduledPrices {
+		if err := change.Validate(); err != nil {
+			validationErrors = append(validationErrors, fmt.Sprintf("scheduled price %d: %v", i+1, err))
+			continue
+		}
+		if !change.EffectiveDate.After(lastChange) {
+			validationErrors = append(validationErrors, fmt.Sprintf("scheduled price %d: must take effect after earlier price changes", i+1))
+		}
+		lastChange = change.EffectiveDate
+	}
+	if plan := snap.Installment; plan != nil {
+		if err := plan.Validate(); err != nil {
+			validationErrors = append(validationErrors, err.Error())
+		} else if plan.Principal.Currency != snap.Cost.Currency {
+			validationErrors = append(validationErrors, "installment principal and payment must be in the same currency")
+		}
+		if snap.TotalPayments == OpenEnded {
+			validationErrors = append(validationErrors, "installment plans need a fixed number of payments")
+		}
+	}
+	if promotion := snap.Promotion; promotion != nil {
+		if err := promotion.Validate(); err != nil {
+			validationErrors = append(validationErrors, err.Error())
+		} else if promotion.Price.Currency != snap.Cost.Currency {
+			validationErrors = append(validationErrors, "promotional and regular price must be in the same currency")
+		}
+	}
+	if snap.NoticeDays < 0 {
+		validationErrors = append(validationErrors, "notice period cannot be negative")
+	}
+	if snap.TotalPayments < 0 {
+		validationErrors = append(validationErrors, "total payments cannot be negative")
+	}
+	if snap.RemainingPayments < 0 || snap.RemainingPayments > snap.TotalPayments {
+		validationErrors = append(validationErrors, fmt.Sprintf("remaining payments must be between 0 and %d", snap.TotalPayments))
+	}
+
+	if len(validationErrors) > 0 {
+		return nil, &ValidationError{Errors: validationErrors}
+	}
+
+	return fromSnapshot(snap), nil
+}
+
+// Clone returns an independent copy of the subscription, including its ID
+// and the version it was taken at
+func (s *Subscription) Clone() *Subscription {
+	clone := fromSnapshot(s.Snapshot())
+	clone.version = s.Version()
+	return clone
+}
+
+// Version counts the updates stored for the subscription since it was
+// loaded. Storage compares it to refuse an edited copy taken before a newer
+// update was stored, which would otherwise silently undo that update.
+func (s *Subscription) Version() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.version
+}
+
+// SetVersion records the version storage assigned to the subscription
+func (s *Subscription) SetVersion(version int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.version = version
+}
+
+// fromSnapshot builds a subscription from a snapshot that is known to be valid
+func fromSnapshot(snap SubscriptionSnapshot) *Subscription {
+	sub := &Subscription{
+		id:                snap.ID,
+		name:              snap.Name,
+		category:          snap.Category,
+		paymentMethodID:   snap.PaymentMethodID,
+		cost:              snap.Cost,
+		variable:          snap.Variable,
+		paymentFrequency:  snap.PaymentFrequency,
+		recurrenceStart:   snap.RecurrenceStart,
+		nextPaymentDate:   snap.NextPaymentDate,
+		anchorDay:         snap.AnchorDay,
+		rollConvention:    snap.RollConvention,
+		remainingPayments: snap.RemainingPayments,
+		totalPayments:     snap.TotalPayments,
+		noticeDays:        snap.NoticeDays,
+		ended:             snap.Ended,
+		state:             snap.State,
+		resumeDate:        snap.ResumeDate,
+		accessEndDate:     snap.AccessEndDate,
+		trialEndDate:      snap.TrialEndDate,
+	}
+	// The tags were validated when the snapshot was taken or restored
+	sub.tags, _ = normalizeTags(snap.Tags)
+	sub.participants = append([]Participant(nil), snap.Participants...)
+	for _, payment := range snap.Payments {
+		sub.recordPayment(payment)
+	}
+	sub.priceHistory = append([]PriceChange(nil), snap.PriceHistory...)
+	sub.scheduledPrices = append([]PriceChange(nil), snap.ScheduledPrices...)
+	sub.installment = copyInstallment(snap.Installment)
+	sub.promotion = copyPromotion(snap.Promotion)
+	if snap.Recurrence != "" {
+		// The rule was validated when the snapshot was taken or restored
+		sub.recurrence, _ = ParseRRule(snap.Recurrence)
+	}
+	return sub
+}
+
+func copyInstallment(plan *InstallmentPlan) *InstallmentPlan {
+	if plan == nil {
+		return nil
+	}
+	copied := *plan
+	return &copied
+}
+
+func copyPromotion(promotion *Promotion) *Promotion {
+	if promotion == nil {
+		return nil
+	}
+	copied := *promotion
+	return &copied
+}
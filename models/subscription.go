@@ -1,27 +1,14 @@
 package models
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
 	"strings"
 	"sync"
 	"time"
 )
 
-// Valid payment frequencies
-const (
-	FrequencyDaily   = "daily"
-	FrequencyWeekly  = "weekly"
-	FrequencyMonthly = "monthly"
-	FrequencyYearly  = "yearly"
-)
-
-var ValidFrequencies = map[string]bool{
-	FrequencyDaily:   true,
-	FrequencyWeekly:  true,
-	FrequencyMonthly: true,
-	FrequencyYearly:  true,
-}
-
 // ValidationError represents multiple validation errors
 type ValidationError struct {
 	Errors []string
@@ -34,31 +21,73 @@ func (v *ValidationError) Error() string {
 // Subscription represents a subscription with thread-safe operations
 type Subscription struct {
 	mu                sync.RWMutex
-	name              string    `json:"name"`
-	cost              float64   `json:"cost"`
-	paymentFrequency  string    `json:"payment_frequency"`
-	nextPaymentDate   time.Time `json:"next_payment_date"`
-	remainingPayments int       `json:"remaining_payments"`
-	totalPayments     int       `json:"total_payments"`
+	id                string
+	name              string
+	category          string
+	tags              []string
+	paymentMethodID   string
+	participants      []Participant
+	cost              Money
+	variable          bool
+	paymentFrequency  Frequency
+	recurrence        *RRule
+	recurrenceStart   time.Time
+	nextPaymentDate   time.Time
+	anchorDay         int
+	rollConvention    RollConvention
+	remainingPayments int
+	totalPayments     int
+	noticeDays        int
+	ended             bool
+	state             State
+	resumeDate        time.Time
+	accessEndDate     time.Time
+	trialEndDate      time.Time
+	payments          []Payment
+	priceHistory      []PriceChange
+	scheduledPrices   []PriceChange
+	installment       *InstallmentPlan
+	promotion         *Promotion
+	// version counts the updates stored since loading and is not persisted
+	version int
 }
 
-func NewSubscription(name string, cost float64, frequency string, nextPayment time.Time, totalPayments int) (*Subscription, error) {
+// OpenEnded is the total payment count of subscriptions that renew
+// indefinitely and therefore have no remaining payments counter
+const OpenEnded = 0
+
+// NewID returns a random identifier for a subscription or payment method
+func NewID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		// crypto/rand only fails if the OS entropy source is unavailable
+		panic(fmt.Sprintf("failed to generate subscription ID: %v", err))
+	}
+	return hex.EncodeToString(b)
+}
+
+// NewSubscription creates a subscription with the given number of payments.
+// A totalPayments of OpenEnded creates a subscription that renews indefinitely.
+func NewSubscription(name string, cost Money, frequency Frequency, nextPayment time.Time, totalPayments int) (*Subscription, error) {
 	var validationErrors []string
 
 	if name == "" {
 		validationErrors = append(validationErrors, "subscription name cannot be empty")
 	}
-	if cost <= 0 {
+	if !cost.IsPositive() {
 		validationErrors = append(validationErrors, "cost must be greater than 0")
 	}
-	if !ValidFrequencies[frequency] {
-		validationErrors = append(validationErrors, "invalid payment frequency: must be one of daily, weekly, monthly, or yearly")
+	if !ValidCurrency(cost.Currency) {
+		validationErrors = append(validationErrors, fmt.Sprintf("unsupported currency '%s'", cost.Currency))
+	}
+	if err := frequency.Validate(); err != nil {
+		validationErrors = append(validationErrors, err.Error())
 	}
 	if nextPayment.Before(time.Now()) {
 		validationErrors = append(validationErrors, "next payment date must be in the future")
 	}
-	if totalPayments <= 0 {
-		validationErrors = append(validationErrors, "total payments must be greater than 0")
+	if totalPayments < 0 {
+		validationErrors = append(validationErrors, "total payments cannot be negative")
 	}
 
 	if len(validationErrors) > 0 {
@@ -66,29 +95,38 @@ func NewSubscription(name string, cost float64, frequency string, nextPayment ti
 	}
 
 	return &Subscription{
+		id:                NewID(),
 		name:              name,
 		cost:              cost,
 		paymentFrequency:  frequency,
 		nextPaymentDate:   nextPayment,
+		anchorDay:         nextPayment.Day(),
 		remainingPayments: totalPayments,
 		totalPayments:     totalPayments,
+		state:             StateActive,
 	}, nil
 }
 
 // Getters with read locks
+func (s *Subscription) ID() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.id
+}
+
 func (s *Subscription) Name() string {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 	return s.name
 }
 
-func (s *Subscription) Cost() float64 {
+func (s *Subscription) Cost() Money {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 	return s.cost
 }
 
-func (s *Subscription) PaymentFrequency() string {
+func (s *Subscription) PaymentFrequency() Frequency {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 	return s.paymentFrequency
@@ -112,6 +150,25 @@ func (s *Subscription) TotalPayments() int {
 	return s.totalPayments
 }
 
+// IsOpenEnded reports whether the subscription renews indefinitely
+func (s *Subscription) IsOpenEnded() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.totalPayments == OpenEnded
+}
+
+// HasEnded reports whether no further payments will be made, either because
+// all fixed payments were made or because the recurrence rule ran out
+func (s *Subscription) HasEnded() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.hasEnded()
+}
+
+func (s *Subscription) hasEnded() bool {
+	return s.ended || (s.totalPayments != OpenEnded && s.remainingPayments <= 0)
+}
+
 // Setters with write locks and validation
 func (s *Subscription) SetName(name string) error {
 	if name == "" {
@@ -123,88 +180,367 @@ func (s *Subscription) SetName(name string) error {
 	return nil
 }
 
-func (s *Subscription) SetCost(cost float64) error {
-	if cost <= 0 {
+// SetCost changes the price from now on. The previous price is kept in the
+// price history; use SchedulePriceChange for changes at a later date.
+func (s *Subscription) SetCost(cost Money) error {
+	if !cost.IsPositive() {
 		return fmt.Errorf("cost must be greater than 0")
 	}
+	if !ValidCurrency(cost.Currency) {
+		return fmt.Errorf("unsupported currency '%s'", cost.Currency)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := validateParticipants(s.participants, cost.Currency); err != nil {
+		return err
+	}
+	if err := s.checkPromotionCurrency(cost.Currency); err != nil {
+		return err
+	}
+	if s.installment != nil && cost != s.cost {
+		return fmt.Errorf("installment plans have a fixed payment; change the plan instead")
+	}
+
+	now := time.Now()
+	// Changes that already took effect come first to keep the history in order
+	s.applyDuePriceChanges(now)
+	if cost == s.cost {
+		return nil
+	}
+	if n := len(s.priceHistory); n > 0 && !now.After(s.priceHistory[n-1].EffectiveDate) {
+		return fmt.Errorf("the price already changed on %s; schedule the new price after that date", s.priceHistory[n-1].EffectiveDate.Format("2006-01-02"))
+	}
+	s.applyPriceChange(PriceChange{EffectiveDate: now, Cost: cost})
+	return nil
+}
+
+func (s *Subscription) SetPaymentFrequency(frequency Frequency) error {
+	if err := frequency.Validate(); err != nil {
+		return err
+	}
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.cost = cost
+	if s.installment != nil && frequency != s.paymentFrequency {
+		return fmt.Errorf("cannot change the billing frequency of an installment plan")
+	}
+	s.paymentFrequency = frequency
 	return nil
 }
 
-func (s *Subscription) TimeUntilNextPayment() time.Duration {
+// SetNextPaymentDate moves the next payment to the given date, which also
+// becomes the billing anchor day for monthly and yearly schedules
+func (s *Subscription) SetNextPaymentDate(date time.Time) error {
+	if date.IsZero() {
+		return fmt.Errorf("next payment date cannot be empty")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if date.Equal(s.nextPaymentDate) {
+		// Keep the anchor of an unchanged date, which may differ from a
+		// clamped day such as Feb 28 for a subscription billed on the 31st
+		return nil
+	}
+	s.nextPaymentDate = date
+	s.anchorDay = date.Day()
+	return nil
+}
+
+// AnchorDay returns the day of the month that monthly and yearly payments
+// return to whenever the month is long enough
+func (s *Subscription) AnchorDay() int {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
-	return time.Until(s.nextPaymentDate)
+	return s.anchorDay
 }
 
-func (s *Subscription) FormattedTimeUntilNextPayment() string {
-	duration := s.TimeUntilNextPayment()
-	days := int(duration.Hours() / 24)
-	hours := int(duration.Hours()) % 24
-	minutes := int(duration.Minutes()) % 60
+// SetAnchorDay sets the billing anchor day. The next payment date must fall
+// on the anchor day, or on the last day of a month too short for it.
+func (s *Subscription) SetAnchorDay(day int) error {
+	if day < 1 || day > 31 {
+		return fmt.Errorf("billing anchor day must be between 1 and 31")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-	return fmt.Sprintf("%dd %dh %dm", days, hours, minutes)
+	if !anchorMatches(s.nextPaymentDate, day) {
+		return fmt.Errorf("billing anchor day %d does not match the next payment date %s", day, s.nextPaymentDate.Format("2006-01-02"))
+	}
+	s.anchorDay = day
+	return nil
 }
 
-// calculateNextPaymentDate handles edge cases in date calculations
-func (s *Subscription) calculateNextPaymentDate() time.Time {
-	current := s.nextPaymentDate
-	switch s.paymentFrequency {
-	case FrequencyDaily:
-		return current.AddDate(0, 0, 1)
-	case FrequencyWeekly:
-		return current.AddDate(0, 0, 7)
-	case FrequencyMonthly:
-		// Handle month end cases
-		year, month, day := current.Date()
-		month++
-		if month > 12 {
-			year++
-			month = 1
-		}
-		// Adjust for months with fewer days
-		lastDay := time.Date(year, month+1, 0, current.Hour(), current.Minute(), current.Second(), current.Nanosecond(), current.Location()).Day()
-		if day > lastDay {
-			day = lastDay
-		}
-		return time.Date(year, month, day, current.Hour(), current.Minute(), current.Second(), current.Nanosecond(), current.Location())
-	case FrequencyYearly:
-		// Handle leap year cases
-		year, month, day := current.Date()
-		if month == 2 && day == 29 && !isLeapYear(year+1) {
-			day = 28
-		}
-		return time.Date(year+1, month, day, current.Hour(), current.Minute(), current.Second(), current.Nanosecond(), current.Location())
-	default:
-		return current
+// SetTotalPayments changes the total number of payments while keeping the
+// payments already made. OpenEnded makes the subscription renew indefinitely;
+// turning an open-ended subscription into a fixed one starts counting afresh.
+func (s *Subscription) SetTotalPayments(total int) error {
+	if total < 0 {
+		return fmt.Errorf("total payments cannot be negative")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.installment != nil && total != s.totalPayments {
+		return fmt.Errorf("cannot change the number of payments of an installment plan")
+	}
+	if total == OpenEnded || s.totalPayments == OpenEnded {
+		s.totalPayments = total
+		s.remainingPayments = total
+		return nil
+	}
+
+	paid := s.totalPayments - s.remainingPayments
+	if paid > total {
+		return fmt.Errorf("total payments cannot be less than the %d payment(s) already made", paid)
+	}
+	s.totalPayments = total
+	s.remainingPayments = total - paid
+	return nil
+}
+
+// Recurrence returns the subscription's recurrence rule, or an empty string
+// when payments follow the payment frequency
+func (s *Subscription) Recurrence() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.recurrence == nil {
+		return ""
+	}
+	return s.recurrence.String()
+}
+
+// RecurrenceStart returns the date the recurrence rule is anchored at
+func (s *Subscription) RecurrenceStart() time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.recurrenceStart
+}
+
+// SetRecurrence makes payments follow an RFC 5545 recurrence rule anchored at
+// start instead of the payment frequency, with the next payment on start. As
+// in RFC 5545, the start is the first occurrence and counts towards COUNT, so
+// it must be a date the rule produces. An empty rule removes the recurrence.
+func (s *Subscription) SetRecurrence(rule string, start time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if rule == "" {
+		s.recurrence = nil
+		s.recurrenceStart = time.Time{}
+		s.ended = false
+		return nil
+	}
+
+	if s.installment != nil {
+		return fmt.Errorf("installment plans follow a billing frequency, not a recurrence rule")
+	}
+	parsed, err := ParseRRule(rule)
+	if err != nil {
+		return err
+	}
+	first, ok := parsed.From(start, start)
+	if !ok {
+		return fmt.Errorf("recurrence rule has no occurrences after %s", start.Format("2006-01-02"))
+	}
+	if !first.Equal(start) {
+		return fmt.Errorf("%s is not an occurrence of the recurrence rule; the first one after it is %s",
+			start.Format("2006-01-02"), first.Format("2006-01-02"))
+	}
+
+	s.recurrence = parsed
+	s.recurrenceStart = start
+	s.nextPaymentDate = first
+	s.anchorDay = first.Day()
+	s.ended = false
+	return nil
+}
+
+// TrialEndDate returns the end of the free trial, or the zero time when the
+// subscription is not in a trial
+func (s *Subscription) TrialEndDate() time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.trialEndDate
+}
+
+// InTrial reports whether the subscription is still in its free trial
+func (s *Subscription) InTrial() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return !s.trialEndDate.IsZero()
+}
+
+// SetTrial puts the subscription in a free trial that ends on the given date.
+// Nothing is charged during the trial, so the next payment, which is the
+// first one at the regular cost, must be due on or after the trial end date.
+// The schedule and billing day are left as they are. A zero date removes the
+// trial.
+func (s *Subscription) SetTrial(end time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if end.IsZero() {
+		s.trialEndDate = time.Time{}
+		return nil
+	}
+	if s.hasEnded() {
+		return fmt.Errorf("cannot start a trial on a subscription that has ended")
+	}
+	if s.nextPaymentDate.Before(end) {
+		return fmt.Errorf("the next payment on %s falls within the trial; move it to the trial end on %s or later",
+			s.nextPaymentDate.Format("2006-01-02"), end.Format("2006-01-02"))
+	}
+
+	s.trialEndDate = end
+	return nil
+}
+
+// EndTrialIfDue converts the subscription to paid once its trial has ended
+// by now, reporting whether it did. The first charge stays on the next
+// payment date, which may follow the trial end.
+func (s *Subscription) EndTrialIfDue(now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.trialEndDate.IsZero() || s.trialEndDate.After(now) {
+		return false
 	}
+	s.trialEndDate = time.Time{}
+	return true
 }
 
-func isLeapYear(year int) bool {
-	return year%4 == 0 && (year%100 != 0 || year%400 == 0)
+// anchorMatches reports whether the date lies on the anchor day, or on the
+// last day of a month that is too short for it
+func anchorMatches(date time.Time, anchorDay int) bool {
+	day := date.Day()
+	lastDay := daysIn(date.Year(), date.Month(), date.Location())
+	return day == anchorDay || (day == lastDay && anchorDay > lastDay)
 }
 
-func (s *Subscription) ProcessPayment() error {
+// calculateNextPaymentDate handles edge cases in date calculations. The
+// boolean is false when a recurrence rule has no further occurrences.
+func (s *Subscription) calculateNextPaymentDate() (time.Time, bool) {
+	if s.recurrence != nil {
+		return s.recurrence.After(s.recurrenceStart, s.nextPaymentDate)
+	}
+	return s.paymentFrequency.NextAnchored(s.nextPaymentDate, s.anchorDay), true
+}
+
+// IsDue reports whether the next payment date, adjusted to a business day in
+// the calendar, has been reached at the given time and the subscription is
+// active with payments left.
+func (s *Subscription) IsDue(now time.Time, calendar *HolidayCalendar) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.currentState() == StateActive && !calendar.Adjust(s.nextPaymentDate, s.rollConvention).After(now)
+}
+
+// ProcessPayment makes the payment that is due, recording it in the ledger
+// on its adjusted due date, and advances the schedule to the next nominal
+// payment date
+func (s *Subscription) ProcessPayment(calendar *HolidayCalendar) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	return s.processPayment(PaymentSourceAuto, calendar.Adjust(s.nextPaymentDate, s.rollConvention))
+}
 
-	if s.remainingPayments <= 0 {
+func (s *Subscription) processPayment(source PaymentSource, paidOn time.Time) error {
+	if s.hasEnded() {
 		return fmt.Errorf("subscription has ended")
 	}
+	if s.state != StateActive {
+		return fmt.Errorf("subscription is %s", s.state)
+	}
+
+	// The first payment after a trial converts the subscription to paid
+	if !s.trialEndDate.IsZero() && !s.nextPaymentDate.Before(s.trialEndDate) {
+		s.trialEndDate = time.Time{}
+	}
+
+	// Price changes effective by the due date apply to this payment. Changes
+	// that are still in the future, as for a payment made early, are charged
+	// but stay scheduled until they take effect.
+	applyUntil := s.nextPaymentDate
+	if now := time.Now(); now.Before(applyUntil) {
+		applyUntil = now
+	}
+	s.applyDuePriceChanges(applyUntil)
+	payment := Payment{Date: paidOn, Amount: s.priceOn(s.nextPaymentDate), Source: source}
+	if s.installment != nil {
+		// The last installment settles what rounding left over
+		payment.Amount = s.installmentAmount()
+	}
+	if price, ok := s.chargePromotion(s.nextPaymentDate); ok {
+		payment.Amount = price
+	}
+	if s.variable {
+		// The charge is only an estimate until the actual amount is entered
+		payment.Estimate = payment.Amount
+		payment.Pending = true
+	}
 
-	s.remainingPayments--
-	s.nextPaymentDate = s.calculateNextPaymentDate()
+	next, ok := s.calculateNextPaymentDate()
+	if !ok {
+		// The recurrence rule has ended, so this was the final payment
+		s.recordPayment(payment)
+		s.remainingPayments = 0
+		s.ended = true
+		return nil
+	}
+	// Guard against schedules that fail to move forward, which would
+	// otherwise keep a catch-up loop spinning forever
+	if !next.After(s.nextPaymentDate) {
+		return fmt.Errorf("unable to advance payment date for frequency '%s'", s.paymentFrequency)
+	}
+
+	s.recordPayment(payment)
+	if s.totalPayments != OpenEnded {
+		s.remainingPayments--
+	}
+	s.advanceTo(next)
+	// A promotion ending before the next payment has run out
+	if s.promotion != nil && !s.promotion.appliesTo(s.nextPaymentDate) {
+		s.promotion = nil
+	}
 	return nil
 }
 
+// advanceTo moves the schedule on to the next payment date
+func (s *Subscription) advanceTo(next time.Time) {
+	s.nextPaymentDate = next
+	// Only monthly and yearly schedules return to their anchor day; other
+	// schedules simply follow the dates they produce
+	if s.recurrence != nil || (s.paymentFrequency.Unit != UnitMonth && s.paymentFrequency.Unit != UnitYear) {
+		s.anchorDay = next.Day()
+	}
+}
+
 func (s *Subscription) Status() string {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	if s.remainingPayments <= 0 {
+	switch s.currentState() {
+	case StateCompleted:
+		if s.installment != nil && s.installment.IsPaidOff() {
+			return fmt.Sprintf("Paid off early on %s", s.installment.PaidOffOn.Format("2006-01-02"))
+		}
 		return "Completed"
+	case StatePaused:
+		if s.resumeDate.IsZero() {
+			return "Paused"
+		}
+		return fmt.Sprintf("Paused (resumes on %s)", s.resumeDate.Format("2006-01-02"))
+	case StateCancelled:
+		if s.accessEndDate.After(time.Now()) {
+			return fmt.Sprintf("Cancelled (access until %s)", s.accessEndDate.Format("2006-01-02"))
+		}
+		return fmt.Sprintf("Cancelled (access ended %s)", s.accessEndDate.Format("2006-01-02"))
+	}
+	if s.trialEndDate.After(time.Now()) {
+		return fmt.Sprintf("Trial (first charge on %s)", s.nextPaymentDate.Format("2006-01-02"))
+	}
+	if s.totalPayments == OpenEnded {
+		return "Active (renews indefinitely)"
 	}
 	return fmt.Sprintf("Active (%d/%d payments remaining)", s.remainingPayments, s.totalPayments)
 }
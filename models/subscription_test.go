@@ -0,0 +1,143 @@
+package models
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSetTrialKeepsSchedule(t *testing.T) {
+	sub := newTestSubscription(t, usd(999), 20, OpenEnded)
+	next := sub.NextPaymentDate()
+	if err := sub.SetAnchorDay(next.Day()); err != nil {
+		t.Fatal(err)
+	}
+
+	// A trial ending before the next payment leaves the schedule alone
+	trialEnd := next.AddDate(0, 0, -10)
+	if err := sub.SetTrial(trialEnd); err != nil {
+		t.Fatal(err)
+	}
+	if !sub.NextPaymentDate().Equal(next) || sub.AnchorDay() != next.Day() {
+		t.Errorf("trial moved the schedule to %s on day %d", sub.NextPaymentDate().Format("2006-01-02"), sub.AnchorDay())
+	}
+	if !sub.InTrial() || !sub.TrialEndDate().Equal(trialEnd) {
+		t.Errorf("trial ends %s, want %s", sub.TrialEndDate().Format("2006-01-02"), trialEnd.Format("2006-01-02"))
+	}
+	restore(t, sub)
+
+	// A trial running past the next payment would charge during the trial
+	if err := sub.SetTrial(next.AddDate(0, 0, 1)); err == nil {
+		t.Error("SetTrial accepted a trial ending after the next payment")
+	}
+	if !sub.TrialEndDate().Equal(trialEnd) {
+		t.Error("rejected trial replaced the existing one")
+	}
+
+	if err := sub.ProcessPayment(nil); err != nil {
+		t.Fatal(err)
+	}
+	if sub.InTrial() {
+		t.Error("first payment after the trial did not end it")
+	}
+}
+
+func TestSetTotalPayments(t *testing.T) {
+	tests := []struct {
+		name          string
+		total         int
+		paid          int
+		newTotal      int
+		wantRemaining int
+		wantErr       bool
+	}{
+		{"open-ended stays open", OpenEnded, 3, OpenEnded, 0, false},
+		{"open-ended counts afresh", OpenEnded, 3, 4, 4, false},
+		{"fixed becomes open-ended", 6, 2, OpenEnded, 0, false},
+		{"fixed keeps paid payments", 6, 2, 10, 8, false},
+		{"fixed down to paid payments", 6, 2, 2, 0, false},
+		{"fixed below paid payments", 6, 2, 1, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub := newTestSubscription(t, usd(999), 20, tt.total)
+			for i := 0; i < tt.paid; i++ {
+				if err := sub.ProcessPayment(nil); err != nil {
+					t.Fatal(err)
+				}
+			}
+			err := sub.SetTotalPayments(tt.newTotal)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("SetTotalPayments succeeded, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sub.TotalPayments() != tt.newTotal || sub.RemainingPayments() != tt.wantRemaining {
+				t.Errorf("got %d of %d payments remaining, want %d of %d", sub.RemainingPayments(), sub.TotalPayments(), tt.wantRemaining, tt.newTotal)
+			}
+			if sub.IsOpenEnded() != (tt.newTotal == OpenEnded) {
+				t.Errorf("IsOpenEnded = %t", sub.IsOpenEnded())
+			}
+			restore(t, sub)
+		})
+	}
+}
+
+func TestOpenEndedNeverCompletes(t *testing.T) {
+	sub := newTestSubscription(t, usd(999), 20, OpenEnded)
+	for i := 0; i < 50; i++ {
+		if err := sub.ProcessPayment(nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if sub.HasEnded() || sub.RemainingPayments() != 0 || len(sub.Payments()) != 50 {
+		t.Errorf("open-ended subscription ended=%t with %d remaining after %d payments",
+			sub.HasEnded(), sub.RemainingPayments(), len(sub.Payments()))
+	}
+
+	fixed := newTestSubscription(t, usd(999), 20, 2)
+	for i := 0; i < 2; i++ {
+		if err := fixed.ProcessPayment(nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !fixed.HasEnded() {
+		t.Error("fixed subscription did not end after its last payment")
+	}
+}
+
+func TestTrialEndsOnItsEndDate(t *testing.T) {
+	sub := newTestSubscription(t, usd(999), 20, OpenEnded)
+	next := sub.NextPaymentDate()
+	if err := sub.SetTrial(next.AddDate(0, 0, -10)); err != nil {
+		t.Fatal(err)
+	}
+	if want := "Trial (first charge on " + next.Format("2006-01-02") + ")"; sub.Status() != want {
+		t.Errorf("Status = %q, want %q", sub.Status(), want)
+	}
+
+	if sub.EndTrialIfDue(next.AddDate(0, 0, -11)) || !sub.InTrial() {
+		t.Error("trial ended before its end date")
+	}
+	if !sub.EndTrialIfDue(next.AddDate(0, 0, -10)) || sub.InTrial() {
+		t.Error("trial did not end on its end date")
+	}
+	if sub.EndTrialIfDue(next) {
+		t.Error("trial ended twice")
+	}
+	if !sub.NextPaymentDate().Equal(next) || len(sub.Payments()) != 0 {
+		t.Error("ending the trial changed the payments")
+	}
+
+	// A trial that has run out shows as active until catch-up converts it
+	if err := sub.SetTrial(Today(time.Now(), time.UTC).AddDate(0, 0, -3)); err != nil {
+		t.Fatal(err)
+	}
+	if status := sub.Status(); strings.HasPrefix(status, "Trial") {
+		t.Errorf("Status = %q after the trial ended", status)
+	}
+}
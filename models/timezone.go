@@ -0,0 +1,84 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// CalendarDate returns midnight in loc of the calendar date t shows in its
+// own location. Payment dates are calendar dates, so moving one to another
+// time zone keeps its day rather than its instant.
+func CalendarDate(t time.Time, loc *time.Location) time.Time {
+	if t.IsZero() {
+		return t
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
+
+// ParseDate parses a YYYY-MM-DD calendar date as midnight in loc
+func ParseDate(value string, loc *time.Location) (time.Time, error) {
+	return time.ParseInLocation("2006-01-02", value, loc)
+}
+
+// Today returns midnight of the current calendar date in loc
+func Today(now time.Time, loc *time.Location) time.Time {
+	return CalendarDate(now.In(loc), loc)
+}
+
+// DaysUntil counts the calendar days in loc from now until the date, which
+// is negative once the date has passed
+func DaysUntil(now, date time.Time, loc *time.Location) int {
+	from := Today(now, loc)
+	to := date.In(loc)
+	// Count in UTC so days shortened or lengthened by DST still count as one
+	fromUTC := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	toUTC := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	return int(toUTC.Sub(fromUTC).Hours() / 24)
+}
+
+// FormatCountdown describes a number of days until a date, e.g. "in 3 days"
+func FormatCountdown(days int) string {
+	switch {
+	case days == 0:
+		return "today"
+	case days == 1:
+		return "tomorrow"
+	case days == -1:
+		return "1 day overdue"
+	case days < 0:
+		return fmt.Sprintf("%d days overdue", -days)
+	default:
+		return fmt.Sprintf("in %d days", days)
+	}
+}
+
+// ValidateTimeZone checks that the name is a time zone known to the system.
+// An empty name stands for the system's local time zone.
+func ValidateTimeZone(name string) error {
+	if _, err := time.LoadLocation(name); err != nil {
+		return fmt.Errorf("unknown time zone '%s'", name)
+	}
+	return nil
+}
+
+// InLocation moves the subscription's calendar dates to midnight of the same
+// days in loc: the schedule, trial, pause and cancellation dates, the
+// promotion's end and the scheduled price changes. Ledger entries and applied
+// price changes are records of when something happened and keep their
+// instants.
+func (s *Subscription) InLocation(loc *time.Location) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextPaymentDate = CalendarDate(s.nextPaymentDate, loc)
+	s.recurrenceStart = CalendarDate(s.recurrenceStart, loc)
+	s.trialEndDate = CalendarDate(s.trialEndDate, loc)
+	s.resumeDate = CalendarDate(s.resumeDate, loc)
+	s.accessEndDate = CalendarDate(s.accessEndDate, loc)
+	if s.promotion != nil {
+		s.promotion.EndDate = CalendarDate(s.promotion.EndDate, loc)
+	}
+	for i := range s.scheduledPrices {
+		s.scheduledPrices[i].EffectiveDate = CalendarDate(s.scheduledPrices[i].EffectiveDate, loc)
+	}
+}
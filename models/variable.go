@@ -0,0 +1,99 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// IsVariable reports whether the subscription's cost changes from payment to
+// payment, in which case the cost is an estimate
+func (s *Subscription) IsVariable() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.variable
+}
+
+// SetVariable marks the cost as an estimate whose actual amount is entered
+// for each payment. Payments already in the ledger are left as they are.
+func (s *Subscription) SetVariable(variable bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if variable && s.installment != nil {
+		return fmt.Errorf("installment plans have a fixed payment and cannot be variable")
+	}
+	s.variable = variable
+	return nil
+}
+
+// PendingPayments returns the payments of a variable subscription whose
+// actual amount has not been entered yet
+func (s *Subscription) PendingPayments() []Payment {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var pending []Payment
+	for _, payment := range s.payments {
+		if payment.Pending {
+			pending = append(pending, payment)
+		}
+	}
+	return pending
+}
+
+// RecordActual replaces the estimate of the pending payment on the given
+// calendar date with the amount actually charged
+func (s *Subscription) RecordActual(date time.Time, actual Money) error {
+	if actual.Amount < 0 {
+		return fmt.Errorf("actual amount cannot be negative")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, payment := range s.payments {
+		if !payment.Pending || dateKey(payment.Date) != dateKey(date) {
+			continue
+		}
+		if actual.Currency != payment.Estimate.Currency {
+			return fmt.Errorf("actual amount must be in %s like the estimate", payment.Estimate.Currency)
+		}
+		s.payments[i].Amount = actual
+		s.payments[i].Pending = false
+		return nil
+	}
+	return fmt.Errorf("no payment awaiting its actual amount on %s", dateKey(date))
+}
+
+// Variance compares the estimate of a variable payment with its actual amount
+type Variance struct {
+	Date       time.Time
+	Estimate   Money
+	Actual     Money
+	Difference Money
+}
+
+// Percent returns the difference as a percentage of the estimate
+func (v Variance) Percent() float64 {
+	if v.Estimate.Amount == 0 {
+		return 0
+	}
+	return float64(v.Difference.Amount) / float64(v.Estimate.Amount) * 100
+}
+
+// Variances returns the variance of every variable payment with a known
+// actual amount dated in [from, to), ordered by date
+func (s *Subscription) Variances(from, to time.Time) []Variance {
+	var variances []Variance
+	for _, payment := range s.PaymentsBetween(from, to) {
+		if payment.Estimate == (Money{}) || payment.Pending {
+			continue
+		}
+		variances = append(variances, Variance{
+			Date:       payment.Date,
+			Estimate:   payment.Estimate,
+			Actual:     payment.Amount,
+			Difference: Money{Amount: payment.Amount.Amount - payment.Estimate.Amount, Currency: payment.Amount.Currency},
+		})
+	}
+	return variances
+}
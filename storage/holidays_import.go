@@ -0,0 +1,91 @@
+package storage
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"subscription-tracker/models"
+	"time"
+)
+
+// ImportHolidays reads a holiday calendar from a CSV or JSON file and returns
+// the holidays in date order.
+//
+// CSV files contain one "date,name" row per holiday with an optional header
+// row; the name column may be left out. Dates use YYYY-MM-DD. JSON files
+// contain an array of objects with "date" and optional "name" keys.
+func ImportHolidays(path string) ([]models.Holiday, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read holidays file: %v", err)
+	}
+
+	var holidays []models.Holiday
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		holidays, err = parseHolidaysJSONFile(data)
+	case ".csv":
+		holidays, err = parseHolidaysCSVFile(data)
+	default:
+		return nil, fmt.Errorf("unsupported holidays file type '%s': use .csv or .json", filepath.Ext(path))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(holidays, func(i, j int) bool {
+		return holidays[i].Date.Before(holidays[j].Date)
+	})
+	return holidays, nil
+}
+
+func parseHolidaysJSONFile(data []byte) ([]models.Holiday, error) {
+	var file []holidayJSON
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid holidays file: %v", err)
+	}
+
+	holidays := make([]models.Holiday, len(file))
+	for i, holiday := range file {
+		parsed, err := holiday.toHoliday()
+		if err != nil {
+			return nil, fmt.Errorf("holiday %d: %v", i+1, err)
+		}
+		holidays[i] = parsed
+	}
+	return holidays, nil
+}
+
+func parseHolidaysCSVFile(data []byte) ([]models.Holiday, error) {
+	reader := csv.NewReader(strings.NewReader(string(data)))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid holidays file: %v", err)
+	}
+
+	var holidays []models.Holiday
+	for i, record := range records {
+		date, err := time.Parse("2006-01-02", strings.TrimSpace(record[0]))
+		if err != nil {
+			// Allow a header row
+			if i == 0 {
+				continue
+			}
+			return nil, fmt.Errorf("line %d: invalid date '%s'", i+1, record[0])
+		}
+
+		holiday := models.Holiday{Date: date}
+		if len(record) > 1 {
+			holiday.Name = strings.TrimSpace(record[1])
+		}
+		holidays = append(holidays, holiday)
+	}
+	return holidays, nil
+}
@@ -3,6 +3,7 @@ package storage
 import (
 	"encoding/json"
 	"fmt"
+	"math"
 	"os"
 	"path/filepath"
 	"subscription-tracker/models"
@@ -11,21 +12,203 @@ import (
 )
 
 type subscriptionJSON struct {
-	Name              string  `json:"name"`
-	Cost              float64 `json:"cost"`
-	PaymentFrequency  string  `json:"payment_frequency"`
-	NextPaymentDate   string  `json:"next_payment_date"`
-	RemainingPayments int     `json:"remaining_payments"`
-	TotalPayments     int     `json:"total_payments"`
+	ID                string            `json:"id"`
+	Name              string            `json:"name"`
+	Category          string            `json:"category,omitempty"`
+	Tags              []string          `json:"tags,omitempty"`
+	PaymentMethodID   string            `json:"payment_method_id,omitempty"`
+	Participants      []participantJSON `json:"participants,omitempty"`
+	Cost              moneyJSON         `json:"cost"`
+	Variable          bool              `json:"variable,omitempty"`
+	Frequency         *frequencyJSON    `json:"frequency,omitempty"`
+	Recurrence        string            `json:"rrule,omitempty"`
+	RecurrenceStart   string            `json:"rrule_start,omitempty"`
+	NextPaymentDate   string            `json:"next_payment_date"`
+	AnchorDay         int               `json:"anchor_day,omitempty"`
+	RollConvention    string            `json:"roll_convention,omitempty"`
+	RemainingPayments int               `json:"remaining_payments,omitempty"`
+	TotalPayments     int               `json:"total_payments,omitempty"`
+	OpenEnded         bool              `json:"open_ended,omitempty"`
+	NoticeDays        int               `json:"notice_days,omitempty"`
+	Ended             bool              `json:"ended,omitempty"`
+	State             string            `json:"state,omitempty"`
+	ResumeDate        string            `json:"resume_date,omitempty"`
+	AccessEndDate     string            `json:"access_end_date,omitempty"`
+	TrialEndDate      string            `json:"trial_end_date,omitempty"`
+	Payments          []paymentJSON     `json:"payments,omitempty"`
+	PriceHistory      []priceJSON       `json:"price_history,omitempty"`
+	ScheduledPrices   []priceJSON       `json:"scheduled_prices,omitempty"`
+	Installment       *installmentJSON  `json:"installment,omitempty"`
+	Promotion         *promotionJSON    `json:"promotion,omitempty"`
+
+	// LegacyFrequency holds the frequency name written before custom
+	// intervals existed. It is only read, and replaced by Frequency on save.
+	LegacyFrequency string `json:"payment_frequency,omitempty"`
+}
+
+type paymentJSON struct {
+	Date     string     `json:"date"`
+	Amount   moneyJSON  `json:"amount"`
+	Source   string     `json:"source"`
+	Estimate *moneyJSON `json:"estimate,omitempty"`
+	Pending  bool       `json:"pending,omitempty"`
+}
+
+type participantJSON struct {
+	Name    string     `json:"name"`
+	Share   string     `json:"share"`
+	Percent float64    `json:"percent,omitempty"`
+	Amount  *moneyJSON `json:"amount,omitempty"`
+}
+
+func toParticipantJSON(participant models.Participant) participantJSON {
+	jsonParticipant := participantJSON{Name: participant.Name, Share: string(participant.Kind)}
+	if participant.Kind == models.SharePercent {
+		jsonParticipant.Percent = participant.Percent
+	} else {
+		amount := toMoneyJSON(participant.Amount)
+		jsonParticipant.Amount = &amount
+	}
+	return jsonParticipant
+}
+
+func (p participantJSON) toParticipant() models.Participant {
+	participant := models.Participant{Name: p.Name, Kind: models.ShareKind(p.Share), Percent: p.Percent}
+	if p.Amount != nil {
+		participant.Amount = p.Amount.toMoney()
+	}
+	return participant
+}
+
+type priceJSON struct {
+	EffectiveDate string     `json:"effective_date"`
+	Cost          moneyJSON  `json:"cost"`
+	Previous      *moneyJSON `json:"previous,omitempty"`
+}
+
+func toPriceJSON(change models.PriceChange) priceJSON {
+	price := priceJSON{
+		EffectiveDate: change.EffectiveDate.Format(time.RFC3339Nano),
+		Cost:          toMoneyJSON(change.Cost),
+	}
+	if change.Previous != (models.Money{}) {
+		previous := toMoneyJSON(change.Previous)
+		price.Previous = &previous
+	}
+	return price
+}
+
+func (p priceJSON) toPriceChange() (models.PriceChange, error) {
+	effective, err := time.Parse(time.RFC3339, p.EffectiveDate)
+	if err != nil {
+		return models.PriceChange{}, fmt.Errorf("invalid price change date format: %v", err)
+	}
+	change := models.PriceChange{EffectiveDate: effective, Cost: p.Cost.toMoney()}
+	if p.Previous != nil {
+		change.Previous = p.Previous.toMoney()
+	}
+	return change, nil
+}
+
+type installmentJSON struct {
+	Principal    moneyJSON `json:"principal"`
+	APR          float64   `json:"apr"`
+	PaidOffOn    string    `json:"paid_off_on,omitempty"`
+	PaidOffAfter int       `json:"paid_off_after,omitempty"`
+}
+
+func toInstallmentJSON(plan models.InstallmentPlan) *installmentJSON {
+	jsonPlan := &installmentJSON{
+		Principal:    toMoneyJSON(plan.Principal),
+		APR:          plan.APR,
+		PaidOffAfter: plan.PaidOffAfter,
+	}
+	if plan.IsPaidOff() {
+		jsonPlan.PaidOffOn = plan.PaidOffOn.Format(time.RFC3339Nano)
+	}
+	return jsonPlan
+}
+
+func (p installmentJSON) toInstallmentPlan() (*models.InstallmentPlan, error) {
+	plan := &models.InstallmentPlan{
+		Principal:    p.Principal.toMoney(),
+		APR:          p.APR,
+		PaidOffAfter: p.PaidOffAfter,
+	}
+	if p.PaidOffOn != "" {
+		paidOff, err := time.Parse(time.RFC3339, p.PaidOffOn)
+		if err != nil {
+			return nil, fmt.Errorf("invalid payoff date format: %v", err)
+		}
+		plan.PaidOffOn = paidOff
+	}
+	return plan, nil
+}
+
+type promotionJSON struct {
+	Price    moneyJSON `json:"price"`
+	Payments int       `json:"payments,omitempty"`
+	EndDate  string    `json:"end_date,omitempty"`
+}
+
+func toPromotionJSON(promotion models.Promotion) *promotionJSON {
+	jsonPromotion := &promotionJSON{
+		Price:    toMoneyJSON(promotion.Price),
+		Payments: promotion.Payments,
+	}
+	if !promotion.EndDate.IsZero() {
+		jsonPromotion.EndDate = promotion.EndDate.Format(time.RFC3339Nano)
+	}
+	return jsonPromotion
+}
+
+type frequencyJSON struct {
+	Unit     string `json:"unit"`
+	Interval int    `json:"interval"`
+}
+
+// moneyJSON stores an exact amount in minor units together with its currency
+type moneyJSON struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+// UnmarshalJSON also accepts the legacy format where cost was a plain
+// decimal number implicitly denominated in the default currency
+func (m *moneyJSON) UnmarshalJSON(data []byte) error {
+	var legacy float64
+	if err := json.Unmarshal(data, &legacy); err == nil {
+		decimals, err := models.CurrencyDecimals(models.DefaultCurrency)
+		if err != nil {
+			return err
+		}
+		m.Amount = int64(math.Round(legacy * math.Pow10(decimals)))
+		m.Currency = models.DefaultCurrency
+		return nil
+	}
+
+	type plain moneyJSON
+	return json.Unmarshal(data, (*plain)(m))
+}
+
+func toMoneyJSON(m models.Money) moneyJSON {
+	return moneyJSON{Amount: m.Amount, Currency: m.Currency}
+}
+
+func (m moneyJSON) toMoney() models.Money {
+	return models.Money{Amount: m.Amount, Currency: m.Currency}
 }
 
 type JSONStorage struct {
 	filePath      string
+	location      *time.Location
 	subscriptions []*models.Subscription
 	mutex         sync.RWMutex
 }
 
-func NewJSONStorage(filePath string) (*JSONStorage, error) {
+// NewJSONStorage loads the subscriptions stored in the file. Their payment
+// dates are read as calendar dates in the given time zone.
+func NewJSONStorage(filePath string, location *time.Location) (*JSONStorage, error) {
 	// Create directory if it doesn't exist
 	dir := filepath.Dir(filePath)
 	if err := os.MkdirAll(dir, 0755); err != nil {
@@ -34,6 +217,7 @@ func NewJSONStorage(filePath string) (*JSONStorage, error) {
 
 	storage := &JSONStorage{
 		filePath:      filePath,
+		location:      location,
 		subscriptions: make([]*models.Subscription, 0),
 	}
 
@@ -59,40 +243,292 @@ func (s *JSONStorage) loadFromFile() error {
 	}
 
 	s.subscriptions = make([]*models.Subscription, 0, len(jsonSubs))
+	seenIDs := make(map[string]bool, len(jsonSubs))
+	migrated := false
 	for _, jsonSub := range jsonSubs {
-		// Parse the date string
-		date, err := time.Parse(time.RFC3339, jsonSub.NextPaymentDate)
+		snap, upgraded, err := jsonSub.toSnapshot(s.location)
 		if err != nil {
-			return fmt.Errorf("invalid date format for subscription %s: %v", jsonSub.Name, err)
+			return fmt.Errorf("invalid data for subscription %s: %v", jsonSub.Name, err)
+		}
+		migrated = migrated || upgraded
+
+		if seenIDs[snap.ID] {
+			return fmt.Errorf("duplicate subscription ID '%s'", snap.ID)
 		}
+		seenIDs[snap.ID] = true
 
-		sub, err := models.NewSubscription(
-			jsonSub.Name,
-			jsonSub.Cost,
-			jsonSub.PaymentFrequency,
-			date,
-			jsonSub.TotalPayments,
-		)
+		sub, err := models.RestoreSubscription(snap)
 		if err != nil {
-			return fmt.Errorf("failed to create subscription from JSON: %v", err)
+			return fmt.Errorf("invalid data for subscription %s: %v", jsonSub.Name, err)
 		}
 		s.subscriptions = append(s.subscriptions, sub)
 	}
 
+	// Write back files in an older format so they are migrated on first load
+	if migrated {
+		if err := s.saveToFile(); err != nil {
+			return fmt.Errorf("failed to save migrated subscriptions: %v", err)
+		}
+	}
+
 	return nil
 }
 
+// toSnapshot converts the stored record into a snapshot, upgrading records
+// written by older versions. Calendar dates are moved to midnight in loc,
+// which also upgrades dates written at UTC midnight before time zones were
+// configurable. The returned flag reports whether an upgrade was needed.
+func (j subscriptionJSON) toSnapshot(loc *time.Location) (models.SubscriptionSnapshot, bool, error) {
+	upgraded := false
+	calendarDate := func(value string) (time.Time, error) {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return time.Time{}, err
+		}
+		date := models.CalendarDate(parsed, loc)
+		upgraded = upgraded || !date.Equal(parsed)
+		return date, nil
+	}
+
+	// Files written before IDs existed get one assigned on first load
+	if j.ID == "" {
+		j.ID = models.NewID()
+		upgraded = true
+	}
+
+	var frequency models.Frequency
+	if j.Frequency != nil {
+		frequency = models.Frequency{Unit: models.FrequencyUnit(j.Frequency.Unit), Interval: j.Frequency.Interval}
+	} else {
+		parsed, err := models.ParseFrequency(j.LegacyFrequency)
+		if err != nil {
+			return models.SubscriptionSnapshot{}, false, err
+		}
+		frequency = parsed
+		upgraded = true
+	}
+
+	date, err := calendarDate(j.NextPaymentDate)
+	if err != nil {
+		return models.SubscriptionSnapshot{}, false, fmt.Errorf("invalid date format: %v", err)
+	}
+
+	// Files written before anchor days existed use the day of the next
+	// payment; an anchor already lost to clamping cannot be recovered
+	if j.AnchorDay == 0 {
+		j.AnchorDay = date.Day()
+		upgraded = true
+	}
+
+	// Files written before lifecycle states existed only hold active ones
+	state := models.State(j.State)
+	if state == "" {
+		state = models.StateActive
+	}
+	var resumeDate, accessEnd time.Time
+	if j.ResumeDate != "" {
+		resumeDate, err = calendarDate(j.ResumeDate)
+		if err != nil {
+			return models.SubscriptionSnapshot{}, false, fmt.Errorf("invalid resume date format: %v", err)
+		}
+	}
+	if j.AccessEndDate != "" {
+		accessEnd, err = calendarDate(j.AccessEndDate)
+		if err != nil {
+			return models.SubscriptionSnapshot{}, false, fmt.Errorf("invalid access end date format: %v", err)
+		}
+	}
+
+	var trialEnd time.Time
+	if j.TrialEndDate != "" {
+		trialEnd, err = calendarDate(j.TrialEndDate)
+		if err != nil {
+			return models.SubscriptionSnapshot{}, false, fmt.Errorf("invalid trial end date format: %v", err)
+		}
+	}
+
+	payments := make([]models.Payment, len(j.Payments))
+	for i, payment := range j.Payments {
+		paidOn, err := time.Parse(time.RFC3339, payment.Date)
+		if err != nil {
+			return models.SubscriptionSnapshot{}, false, fmt.Errorf("invalid payment date format: %v", err)
+		}
+		payments[i] = models.Payment{
+			Date:    paidOn,
+			Amount:  payment.Amount.toMoney(),
+			Source:  models.PaymentSource(payment.Source),
+			Pending: payment.Pending,
+		}
+		if payment.Estimate != nil {
+			payments[i].Estimate = payment.Estimate.toMoney()
+		}
+	}
+
+	participants := make([]models.Participant, len(j.Participants))
+	for i, participant := range j.Participants {
+		participants[i] = participant.toParticipant()
+	}
+
+	priceHistory, err := toPriceChanges(j.PriceHistory)
+	if err != nil {
+		return models.SubscriptionSnapshot{}, false, err
+	}
+	scheduledPrices, err := toPriceChanges(j.ScheduledPrices)
+	if err != nil {
+		return models.SubscriptionSnapshot{}, false, err
+	}
+	var installment *models.InstallmentPlan
+	if j.Installment != nil {
+		installment, err = j.Installment.toInstallmentPlan()
+		if err != nil {
+			return models.SubscriptionSnapshot{}, false, err
+		}
+	}
+	var promotion *models.Promotion
+	if j.Promotion != nil {
+		promotion = &models.Promotion{Price: j.Promotion.Price.toMoney(), Payments: j.Promotion.Payments}
+		if j.Promotion.EndDate != "" {
+			promotion.EndDate, err = calendarDate(j.Promotion.EndDate)
+			if err != nil {
+				return models.SubscriptionSnapshot{}, false, fmt.Errorf("invalid promotion end date format: %v", err)
+			}
+		}
+	}
+	for i, change := range scheduledPrices {
+		scheduledPrices[i].EffectiveDate = models.CalendarDate(change.EffectiveDate, loc)
+		upgraded = upgraded || !scheduledPrices[i].EffectiveDate.Equal(change.EffectiveDate)
+	}
+
+	// Only open-ended subscriptions may omit their payment counts
+	if !j.OpenEnded && j.TotalPayments <= 0 {
+		return models.SubscriptionSnapshot{}, false, fmt.Errorf("total payments must be greater than 0 unless open-ended")
+	}
+	if j.OpenEnded && (j.TotalPayments != 0 || j.RemainingPayments != 0) {
+		return models.SubscriptionSnapshot{}, false, fmt.Errorf("open-ended subscriptions cannot have payment counts")
+	}
+
+	var recurrenceStart time.Time
+	if j.RecurrenceStart != "" {
+		recurrenceStart, err = calendarDate(j.RecurrenceStart)
+		if err != nil {
+			return models.SubscriptionSnapshot{}, false, fmt.Errorf("invalid recurrence start format: %v", err)
+		}
+	}
+
+	return models.SubscriptionSnapshot{
+		ID:                j.ID,
+		Name:              j.Name,
+		Category:          j.Category,
+		Tags:              j.Tags,
+		PaymentMethodID:   j.PaymentMethodID,
+		Participants:      participants,
+		Cost:              j.Cost.toMoney(),
+		Variable:          j.Variable,
+		PaymentFrequency:  frequency,
+		Recurrence:        j.Recurrence,
+		RecurrenceStart:   recurrenceStart,
+		NextPaymentDate:   date,
+		AnchorDay:         j.AnchorDay,
+		RollConvention:    models.RollConvention(j.RollConvention),
+		RemainingPayments: j.RemainingPayments,
+		TotalPayments:     j.TotalPayments,
+		NoticeDays:        j.NoticeDays,
+		Ended:             j.Ended,
+		State:             state,
+		ResumeDate:        resumeDate,
+		AccessEndDate:     accessEnd,
+		TrialEndDate:      trialEnd,
+		Payments:          payments,
+		PriceHistory:      priceHistory,
+		ScheduledPrices:   scheduledPrices,
+		Installment:       installment,
+		Promotion:         promotion,
+	}, upgraded, nil
+}
+
+func toPriceChanges(prices []priceJSON) ([]models.PriceChange, error) {
+	changes := make([]models.PriceChange, len(prices))
+	for i, price := range prices {
+		change, err := price.toPriceChange()
+		if err != nil {
+			return nil, err
+		}
+		changes[i] = change
+	}
+	return changes, nil
+}
+
+func toSubscriptionJSON(snap models.SubscriptionSnapshot) subscriptionJSON {
+	jsonSub := subscriptionJSON{
+		ID:                snap.ID,
+		Name:              snap.Name,
+		Category:          snap.Category,
+		Tags:              snap.Tags,
+		PaymentMethodID:   snap.PaymentMethodID,
+		Cost:              toMoneyJSON(snap.Cost),
+		Variable:          snap.Variable,
+		Frequency:         &frequencyJSON{Unit: string(snap.PaymentFrequency.Unit), Interval: snap.PaymentFrequency.Interval},
+		NextPaymentDate:   snap.NextPaymentDate.Format(time.RFC3339Nano),
+		AnchorDay:         snap.AnchorDay,
+		RollConvention:    string(snap.RollConvention),
+		RemainingPayments: snap.RemainingPayments,
+		TotalPayments:     snap.TotalPayments,
+		OpenEnded:         snap.TotalPayments == models.OpenEnded,
+		NoticeDays:        snap.NoticeDays,
+		Ended:             snap.Ended,
+	}
+	for _, payment := range snap.Payments {
+		jsonPayment := paymentJSON{
+			Date:    payment.Date.Format(time.RFC3339Nano),
+			Amount:  toMoneyJSON(payment.Amount),
+			Source:  string(payment.Source),
+			Pending: payment.Pending,
+		}
+		if payment.Estimate != (models.Money{}) {
+			estimate := toMoneyJSON(payment.Estimate)
+			jsonPayment.Estimate = &estimate
+		}
+		jsonSub.Payments = append(jsonSub.Payments, jsonPayment)
+	}
+	for _, participant := range snap.Participants {
+		jsonSub.Participants = append(jsonSub.Participants, toParticipantJSON(participant))
+	}
+	for _, change := range snap.PriceHistory {
+		jsonSub.PriceHistory = append(jsonSub.PriceHistory, toPriceJSON(change))
+	}
+	for _, change := range snap.ScheduledPrices {
+		jsonSub.ScheduledPrices = append(jsonSub.ScheduledPrices, toPriceJSON(change))
+	}
+	if snap.Installment != nil {
+		jsonSub.Installment = toInstallmentJSON(*snap.Installment)
+	}
+	if snap.Promotion != nil {
+		jsonSub.Promotion = toPromotionJSON(*snap.Promotion)
+	}
+	if snap.State != models.StateActive {
+		jsonSub.State = string(snap.State)
+	}
+	if !snap.ResumeDate.IsZero() {
+		jsonSub.ResumeDate = snap.ResumeDate.Format(time.RFC3339Nano)
+	}
+	if !snap.AccessEndDate.IsZero() {
+		jsonSub.AccessEndDate = snap.AccessEndDate.Format(time.RFC3339Nano)
+	}
+	if !snap.TrialEndDate.IsZero() {
+		jsonSub.TrialEndDate = snap.TrialEndDate.Format(time.RFC3339Nano)
+	}
+	if snap.Recurrence != "" {
+		jsonSub.Recurrence = snap.Recurrence
+		jsonSub.RecurrenceStart = snap.RecurrenceStart.Format(time.RFC3339Nano)
+	}
+	return jsonSub
+}
+
 func (s *JSONStorage) saveToFile() error {
 	jsonSubs := make([]subscriptionJSON, len(s.subscriptions))
 	for i, sub := range s.subscriptions {
-		jsonSubs[i] = subscriptionJSON{
-			Name:              sub.Name(),
-			Cost:              sub.Cost(),
-			PaymentFrequency:  sub.PaymentFrequency(),
-			NextPaymentDate:   sub.NextPaymentDate().Format(time.RFC3339),
-			RemainingPayments: sub.RemainingPayments(),
-			TotalPayments:     sub.TotalPayments(),
-		}
+		// Take a single snapshot so all fields come from the same state
+		jsonSubs[i] = toSubscriptionJSON(sub.Snapshot())
 	}
 
 	data, err := json.MarshalIndent(jsonSubs, "", "  ")
@@ -111,10 +547,10 @@ func (s *JSONStorage) AddSubscription(sub *models.Subscription) error {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
-	// Check for duplicate names
+	// Check for duplicate IDs
 	for _, existing := range s.subscriptions {
-		if existing.Name() == sub.Name() {
-			return fmt.Errorf("subscription with name '%s' already exists", sub.Name())
+		if existing.ID() == sub.ID() {
+			return fmt.Errorf("subscription with ID '%s' already exists", sub.ID())
 		}
 	}
 
@@ -139,47 +575,59 @@ func (s *JSONStorage) GetSubscriptions() []*models.Subscription {
 	return result
 }
 
-func (s *JSONStorage) UpdateSubscription(name string, updatedSub *models.Subscription) error {
+func (s *JSONStorage) GetSubscription(id string) (*models.Subscription, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	for _, sub := range s.subscriptions {
+		if sub.ID() == id {
+			return sub, nil
+		}
+	}
+	return nil, fmt.Errorf("subscription with ID '%s' not found", id)
+}
+
+func (s *JSONStorage) UpdateSubscription(id string, updatedSub *models.Subscription) error {
 	if updatedSub == nil {
 		return fmt.Errorf("updated subscription cannot be nil")
 	}
+	if updatedSub.ID() != id {
+		return fmt.Errorf("subscription ID cannot be changed")
+	}
 
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
 	for i, sub := range s.subscriptions {
-		if sub.Name() == name {
-			// If the name is being changed, check for duplicates
-			if name != updatedSub.Name() {
-				for _, existing := range s.subscriptions {
-					if existing.Name() == updatedSub.Name() {
-						return fmt.Errorf("subscription with name '%s' already exists", updatedSub.Name())
-					}
-				}
+		if sub.ID() == id {
+			if err := checkVersion(sub, updatedSub); err != nil {
+				return err
 			}
 
 			// Store old subscription in case save fails
 			oldSub := s.subscriptions[i]
+			updatedSub.SetVersion(oldSub.Version() + 1)
 			s.subscriptions[i] = updatedSub
 
 			if err := s.saveToFile(); err != nil {
 				// Restore old subscription if save fails
 				s.subscriptions[i] = oldSub
+				updatedSub.SetVersion(oldSub.Version())
 				return fmt.Errorf("failed to save subscription update: %v", err)
 			}
 
 			return nil
 		}
 	}
-	return fmt.Errorf("subscription with name '%s' not found", name)
+	return fmt.Errorf("subscription with ID '%s' not found", id)
 }
 
-func (s *JSONStorage) DeleteSubscription(name string) error {
+func (s *JSONStorage) DeleteSubscription(id string) error {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
 	for i, sub := range s.subscriptions {
-		if sub.Name() == name {
+		if sub.ID() == id {
 			// Store subscription and index in case save fails
 			oldSub := sub
 			oldIndex := i
@@ -196,5 +644,5 @@ func (s *JSONStorage) DeleteSubscription(name string) error {
 			return nil
 		}
 	}
-	return fmt.Errorf("subscription with name '%s' not found", name)
+	return fmt.Errorf("subscription with ID '%s' not found", id)
 }
@@ -0,0 +1,180 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"subscription-tracker/models"
+	"testing"
+	"time"
+)
+
+func newTestStorage(t *testing.T) (*JSONStorage, *models.Subscription) {
+	t.Helper()
+	store, err := NewJSONStorage(filepath.Join(t.TempDir(), "subscriptions.json"), time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	next := models.Today(time.Now(), time.UTC).AddDate(0, 0, 10)
+	sub, err := models.NewSubscription("Test", models.Money{Amount: 999, Currency: "USD"}, models.FrequencyMonthly, next, models.OpenEnded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.AddSubscription(sub); err != nil {
+		t.Fatal(err)
+	}
+	return store, sub
+}
+
+func TestUpdateRejectsStaleCopies(t *testing.T) {
+	store, sub := newTestStorage(t)
+
+	first, second := sub.Clone(), sub.Clone()
+	if err := first.ProcessPayment(nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.UpdateSubscription(first.ID(), first); err != nil {
+		t.Fatal(err)
+	}
+
+	// The second copy predates the payment and would drop it
+	if err := second.SetName("Renamed"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.UpdateSubscription(second.ID(), second); !errors.Is(err, ErrStale) {
+		t.Errorf("stale update returned %v, want ErrStale", err)
+	}
+	stored, err := store.GetSubscription(sub.ID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stored.Payments()) != 1 || stored.Name() != "Test" {
+		t.Errorf("stale update replaced the stored subscription")
+	}
+
+	// A copy taken after the update is accepted
+	third := stored.Clone()
+	if err := third.SetName("Renamed"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.UpdateSubscription(third.ID(), third); err != nil {
+		t.Errorf("fresh update failed: %v", err)
+	}
+
+	if err := store.UpdateSubscription(third.ID(), third); err == nil {
+		t.Error("update of the stored subscription itself was accepted")
+	}
+}
+
+func TestUpdateRollsBackFailedSave(t *testing.T) {
+	store, sub := newTestStorage(t)
+
+	// A directory in place of the file makes every save fail
+	if err := os.Remove(store.filePath); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(store.filePath, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	updated := sub.Clone()
+	if err := updated.SetName("Renamed"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.UpdateSubscription(updated.ID(), updated); err == nil {
+		t.Fatal("update succeeded without saving")
+	}
+	stored, err := store.GetSubscription(sub.ID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored != sub || stored.Name() != "Test" {
+		t.Error("failed update was not rolled back")
+	}
+
+	// The copy can be retried once saving works again
+	if err := os.Remove(store.filePath); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.UpdateSubscription(updated.ID(), updated); err != nil {
+		t.Errorf("retried update failed: %v", err)
+	}
+}
+
+func TestSubscriptionsSurviveReload(t *testing.T) {
+	usd := func(amount int64) models.Money { return models.Money{Amount: amount, Currency: "USD"} }
+	next := models.Today(time.Now(), time.UTC).AddDate(0, 0, 10)
+	newSub := func(name string, totalPayments int) *models.Subscription {
+		t.Helper()
+		sub, err := models.NewSubscription(name, usd(1500), models.FrequencyMonthly, next, totalPayments)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return sub
+	}
+	check := func(err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Shared, variable subscription in a trial with changing prices
+	shared := newSub("Video", models.OpenEnded)
+	check(shared.SetTrial(next.AddDate(0, 0, -5)))
+	check(shared.SetTags([]string{"video", "family"}))
+	check(shared.SetCategory("Entertainment"))
+	check(shared.SetNoticeDays(3))
+	check(shared.SetRollConvention(models.RollFollowing))
+	check(shared.SetVariable(true))
+	check(shared.ProcessPayment(nil))
+	check(shared.RecordActual(next, usd(1050)))
+	check(shared.SetCost(usd(1199)))
+	check(shared.SchedulePriceChange(usd(1299), next.AddDate(0, 3, 0)))
+	check(shared.SetParticipants([]models.Participant{
+		{Name: "Alex", Kind: models.SharePercent, Percent: 25},
+		{Name: "Sam", Kind: models.ShareFixed, Amount: usd(300)},
+	}))
+
+	plan := newSub("Laptop", 12)
+	check(plan.SetInstallment(usd(120000), 12))
+	check(plan.ProcessPayment(nil))
+
+	promo := newSub("Streaming", models.OpenEnded)
+	check(promo.SetPromotion(models.Promotion{Price: usd(499), Payments: 3, EndDate: next.AddDate(0, 6, 0)}))
+	check(promo.Cancel(time.Time{}))
+
+	custom := newSub("Cleaning", models.OpenEnded)
+	rule, err := models.ParseRRule("FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH")
+	check(err)
+	start, _ := rule.From(next, next)
+	check(custom.SetRecurrence(rule.String(), start))
+	check(custom.Pause(next.AddDate(0, 1, 0)))
+
+	filePath := filepath.Join(t.TempDir(), "subscriptions.json")
+	store, err := NewJSONStorage(filePath, time.UTC)
+	check(err)
+	for _, sub := range []*models.Subscription{shared, plan, promo, custom} {
+		check(store.AddSubscription(sub))
+	}
+
+	reloaded, err := NewJSONStorage(filePath, time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(reloaded.GetSubscriptions()); got != 4 {
+		t.Fatalf("reloaded %d subscriptions, want 4", got)
+	}
+	for _, want := range store.GetSubscriptions() {
+		got, err := reloaded.GetSubscription(want.ID())
+		if err != nil {
+			t.Errorf("%s: %v", want.Name(), err)
+			continue
+		}
+		// The file format is the reference for what must be kept
+		if !reflect.DeepEqual(toSubscriptionJSON(got.Snapshot()), toSubscriptionJSON(want.Snapshot())) {
+			t.Errorf("%s changed on reload:\n got %+v\nwant %+v", want.Name(), got.Snapshot(), want.Snapshot())
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strings"
+	"subscription-tracker/models"
+	"time"
+)
+
+// ImportPayments reads historical payments from a CSV file with one
+// "date,amount[,currency]" row per payment, where the date is YYYY-MM-DD in
+// the given time zone and the currency defaults to the given one. A header
+// row is allowed.
+func ImportPayments(path string, defaultCurrency string, loc *time.Location) ([]models.Payment, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open payments file: %v", err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid payments file: %v", err)
+	}
+
+	var payments []models.Payment
+	for i, record := range records {
+		if len(record) < 2 {
+			return nil, fmt.Errorf("line %d: expected date and amount", i+1)
+		}
+
+		date, err := models.ParseDate(strings.TrimSpace(record[0]), loc)
+		if err != nil {
+			// Allow a header row
+			if i == 0 {
+				continue
+			}
+			return nil, fmt.Errorf("line %d: invalid date '%s'", i+1, record[0])
+		}
+
+		currency := defaultCurrency
+		if len(record) > 2 && strings.TrimSpace(record[2]) != "" {
+			currency = strings.ToUpper(strings.TrimSpace(record[2]))
+		}
+		amount, err := models.ParseMoney(record[1], currency)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", i+1, err)
+		}
+
+		payments = append(payments, models.Payment{
+			Date:   date,
+			Amount: amount,
+			Source: models.PaymentSourceImported,
+		})
+	}
+	return payments, nil
+}
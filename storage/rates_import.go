@@ -0,0 +1,99 @@
+package storage
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"subscription-tracker/models"
+	"time"
+)
+
+// ratesFileJSON is the format accepted when importing rates from a JSON file.
+// Base is optional and defaults to the configured base currency.
+type ratesFileJSON struct {
+	Base  string             `json:"base"`
+	Date  string             `json:"date"`
+	Rates map[string]float64 `json:"rates"`
+}
+
+// ImportExchangeRates reads an exchange rate table from a CSV or JSON file and
+// returns it expressed in the given base currency.
+//
+// CSV files contain one "currency,rate" row per currency with an optional
+// header row and an optional third column holding the rate date (YYYY-MM-DD).
+// JSON files contain an object with "base", "date" and "rates" keys.
+func ImportExchangeRates(path string, base string) (models.ExchangeRates, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return models.ExchangeRates{}, fmt.Errorf("failed to read rates file: %v", err)
+	}
+
+	var rates models.ExchangeRates
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		rates, err = parseRatesJSONFile(data, base)
+	case ".csv":
+		rates, err = parseRatesCSVFile(data, base)
+	default:
+		return models.ExchangeRates{}, fmt.Errorf("unsupported rates file type '%s': use .csv or .json", filepath.Ext(path))
+	}
+	if err != nil {
+		return models.ExchangeRates{}, err
+	}
+
+	return rates.Rebase(base)
+}
+
+func parseRatesJSONFile(data []byte, base string) (models.ExchangeRates, error) {
+	var file ratesFileJSON
+	if err := json.Unmarshal(data, &file); err != nil {
+		return models.ExchangeRates{}, fmt.Errorf("invalid rates file: %v", err)
+	}
+	if file.Base == "" {
+		file.Base = base
+	}
+	return parseExchangeRatesJSON(strings.ToUpper(file.Base), exchangeRatesJSON{Date: file.Date, Rates: file.Rates})
+}
+
+func parseRatesCSVFile(data []byte, base string) (models.ExchangeRates, error) {
+	reader := csv.NewReader(strings.NewReader(string(data)))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return models.ExchangeRates{}, fmt.Errorf("invalid rates file: %v", err)
+	}
+
+	rates := make(map[string]float64)
+	var date time.Time
+	for i, record := range records {
+		if len(record) < 2 {
+			return models.ExchangeRates{}, fmt.Errorf("line %d: expected currency and rate", i+1)
+		}
+
+		rate, err := strconv.ParseFloat(strings.TrimSpace(record[1]), 64)
+		if err != nil {
+			// Allow a header row
+			if i == 0 {
+				continue
+			}
+			return models.ExchangeRates{}, fmt.Errorf("line %d: invalid rate '%s'", i+1, record[1])
+		}
+		rates[strings.ToUpper(strings.TrimSpace(record[0]))] = rate
+
+		if len(record) > 2 && strings.TrimSpace(record[2]) != "" {
+			parsed, err := time.Parse("2006-01-02", strings.TrimSpace(record[2]))
+			if err != nil {
+				return models.ExchangeRates{}, fmt.Errorf("line %d: invalid date '%s'", i+1, record[2])
+			}
+			date = parsed
+		}
+	}
+
+	return models.NewExchangeRates(base, date, rates)
+}
@@ -0,0 +1,220 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"subscription-tracker/models"
+	"sync"
+	"time"
+)
+
+// SettingsStorage persists the user's application-wide settings
+type SettingsStorage interface {
+	GetSettings() models.Settings
+	SaveSettings(settings models.Settings) error
+}
+
+type exchangeRatesJSON struct {
+	Date  string             `json:"date,omitempty"`
+	Rates map[string]float64 `json:"rates"`
+}
+
+type paymentMethodJSON struct {
+	ID          string `json:"id"`
+	Label       string `json:"label"`
+	Type        string `json:"type"`
+	LastFour    string `json:"last_four,omitempty"`
+	ExpiryMonth int    `json:"expiry_month,omitempty"`
+	ExpiryYear  int    `json:"expiry_year,omitempty"`
+}
+
+type budgetJSON struct {
+	Category string    `json:"category"`
+	Monthly  moneyJSON `json:"monthly"`
+}
+
+type holidayJSON struct {
+	Date string `json:"date"`
+	Name string `json:"name,omitempty"`
+}
+
+type settingsJSON struct {
+	BaseCurrency   string              `json:"base_currency"`
+	ExchangeRates  exchangeRatesJSON   `json:"exchange_rates"`
+	PaymentMethods []paymentMethodJSON `json:"payment_methods,omitempty"`
+	CostPeriod     string              `json:"cost_period,omitempty"`
+	Budgets        []budgetJSON        `json:"budgets,omitempty"`
+	Holidays       []holidayJSON       `json:"holidays,omitempty"`
+	TimeZone       string              `json:"time_zone,omitempty"`
+}
+
+type JSONSettingsStorage struct {
+	filePath string
+	settings models.Settings
+	mutex    sync.RWMutex
+}
+
+func NewJSONSettingsStorage(filePath string) (*JSONSettingsStorage, error) {
+	// Create directory if it doesn't exist
+	dir := filepath.Dir(filePath)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory: %v", err)
+	}
+
+	storage := &JSONSettingsStorage{
+		filePath: filePath,
+		settings: models.DefaultSettings(),
+	}
+
+	// Load existing settings if file exists
+	if _, err := os.Stat(filePath); err == nil {
+		if err := storage.loadFromFile(); err != nil {
+			return nil, fmt.Errorf("failed to load settings: %v", err)
+		}
+	}
+
+	return storage, nil
+}
+
+func (s *JSONSettingsStorage) loadFromFile() error {
+	data, err := os.ReadFile(s.filePath)
+	if err != nil {
+		return err
+	}
+
+	var jsonSettings settingsJSON
+	if err := json.Unmarshal(data, &jsonSettings); err != nil {
+		return err
+	}
+
+	settings := models.DefaultSettings()
+	if jsonSettings.BaseCurrency != "" {
+		settings.BaseCurrency = jsonSettings.BaseCurrency
+	}
+	if jsonSettings.CostPeriod != "" {
+		settings.CostPeriod = models.Period(jsonSettings.CostPeriod)
+	}
+	settings.TimeZone = jsonSettings.TimeZone
+
+	rates, err := parseExchangeRatesJSON(settings.BaseCurrency, jsonSettings.ExchangeRates)
+	if err != nil {
+		return err
+	}
+	settings.ExchangeRates = rates
+
+	for _, budget := range jsonSettings.Budgets {
+		settings.Budgets = append(settings.Budgets, models.Budget{
+			Category: budget.Category,
+			Monthly:  budget.Monthly.toMoney(),
+		})
+	}
+	for _, holiday := range jsonSettings.Holidays {
+		parsed, err := holiday.toHoliday()
+		if err != nil {
+			return err
+		}
+		settings.Holidays = append(settings.Holidays, parsed)
+	}
+	for _, method := range jsonSettings.PaymentMethods {
+		settings.PaymentMethods = append(settings.PaymentMethods, models.PaymentMethod{
+			ID:          method.ID,
+			Label:       method.Label,
+			Type:        models.PaymentMethodType(method.Type),
+			LastFour:    method.LastFour,
+			ExpiryMonth: method.ExpiryMonth,
+			ExpiryYear:  method.ExpiryYear,
+		})
+	}
+
+	if err := settings.Validate(); err != nil {
+		return err
+	}
+	s.settings = settings
+	return nil
+}
+
+func parseExchangeRatesJSON(base string, jsonRates exchangeRatesJSON) (models.ExchangeRates, error) {
+	var date time.Time
+	if jsonRates.Date != "" {
+		parsed, err := time.Parse("2006-01-02", jsonRates.Date)
+		if err != nil {
+			return models.ExchangeRates{}, fmt.Errorf("invalid exchange rate date: %v", err)
+		}
+		date = parsed
+	}
+	return models.NewExchangeRates(base, date, jsonRates.Rates)
+}
+
+func (h holidayJSON) toHoliday() (models.Holiday, error) {
+	date, err := time.Parse("2006-01-02", h.Date)
+	if err != nil {
+		return models.Holiday{}, fmt.Errorf("invalid holiday date: %v", err)
+	}
+	return models.Holiday{Date: date, Name: h.Name}, nil
+}
+
+func (s *JSONSettingsStorage) saveToFile(settings models.Settings) error {
+	jsonSettings := settingsJSON{
+		BaseCurrency: settings.BaseCurrency,
+		CostPeriod:   string(settings.CostPeriod),
+		TimeZone:     settings.TimeZone,
+		ExchangeRates: exchangeRatesJSON{
+			Rates: settings.ExchangeRates.Rates,
+		},
+	}
+	if !settings.ExchangeRates.Date.IsZero() {
+		jsonSettings.ExchangeRates.Date = settings.ExchangeRates.Date.Format("2006-01-02")
+	}
+	for _, budget := range settings.Budgets {
+		jsonSettings.Budgets = append(jsonSettings.Budgets, budgetJSON{
+			Category: budget.Category,
+			Monthly:  toMoneyJSON(budget.Monthly),
+		})
+	}
+	for _, holiday := range settings.Holidays {
+		jsonSettings.Holidays = append(jsonSettings.Holidays, holidayJSON{
+			Date: holiday.Date.Format("2006-01-02"),
+			Name: holiday.Name,
+		})
+	}
+	for _, method := range settings.PaymentMethods {
+		jsonSettings.PaymentMethods = append(jsonSettings.PaymentMethods, paymentMethodJSON{
+			ID:          method.ID,
+			Label:       method.Label,
+			Type:        string(method.Type),
+			LastFour:    method.LastFour,
+			ExpiryMonth: method.ExpiryMonth,
+			ExpiryYear:  method.ExpiryYear,
+		})
+	}
+
+	data, err := json.MarshalIndent(jsonSettings, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(s.filePath, data, 0644)
+}
+
+func (s *JSONSettingsStorage) GetSettings() models.Settings {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.settings
+}
+
+func (s *JSONSettingsStorage) SaveSettings(settings models.Settings) error {
+	if err := settings.Validate(); err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.saveToFile(settings); err != nil {
+		return fmt.Errorf("failed to save settings: %v", err)
+	}
+	s.settings = settings
+	return nil
+}
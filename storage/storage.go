@@ -1,16 +1,25 @@
 package storage
 
 import (
+	"errors"
 	"fmt"
 	"subscription-tracker/models"
 	"sync"
 )
 
+// ErrStale is returned when an update was made to a copy of a subscription
+// that has been updated since the copy was taken, e.g. by a processed payment
+var ErrStale = errors.New("the subscription was changed in the meantime; reopen it and try again")
+
+// Storage persists subscriptions, addressing them by their ID. Stored
+// subscriptions are not changed in place: updates are made to a Clone and
+// rejected with ErrStale when another update was stored in the meantime.
 type Storage interface {
 	AddSubscription(sub *models.Subscription) error
 	GetSubscriptions() []*models.Subscription
-	UpdateSubscription(name string, updatedSub *models.Subscription) error
-	DeleteSubscription(name string) error
+	GetSubscription(id string) (*models.Subscription, error)
+	UpdateSubscription(id string, updatedSub *models.Subscription) error
+	DeleteSubscription(id string) error
 }
 
 type MemoryStorage struct {
@@ -32,10 +41,10 @@ func (s *MemoryStorage) AddSubscription(sub *models.Subscription) error {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
-	// Check for duplicate names
+	// Check for duplicate IDs
 	for _, existing := range s.subscriptions {
-		if existing.Name() == sub.Name() {
-			return fmt.Errorf("subscription with name '%s' already exists", sub.Name())
+		if existing.ID() == sub.ID() {
+			return fmt.Errorf("subscription with ID '%s' already exists", sub.ID())
 		}
 	}
 
@@ -53,41 +62,64 @@ func (s *MemoryStorage) GetSubscriptions() []*models.Subscription {
 	return result
 }
 
-func (s *MemoryStorage) UpdateSubscription(name string, updatedSub *models.Subscription) error {
+func (s *MemoryStorage) GetSubscription(id string) (*models.Subscription, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	for _, sub := range s.subscriptions {
+		if sub.ID() == id {
+			return sub, nil
+		}
+	}
+	return nil, fmt.Errorf("subscription with ID '%s' not found", id)
+}
+
+func (s *MemoryStorage) UpdateSubscription(id string, updatedSub *models.Subscription) error {
 	if updatedSub == nil {
 		return fmt.Errorf("updated subscription cannot be nil")
 	}
+	if updatedSub.ID() != id {
+		return fmt.Errorf("subscription ID cannot be changed")
+	}
 
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
 	for i, sub := range s.subscriptions {
-		if sub.Name() == name {
-			// If the name is being changed, check for duplicates
-			if name != updatedSub.Name() {
-				for _, existing := range s.subscriptions {
-					if existing.Name() == updatedSub.Name() {
-						return fmt.Errorf("subscription with name '%s' already exists", updatedSub.Name())
-					}
-				}
+		if sub.ID() == id {
+			if err := checkVersion(sub, updatedSub); err != nil {
+				return err
 			}
+			updatedSub.SetVersion(sub.Version() + 1)
 			s.subscriptions[i] = updatedSub
 			return nil
 		}
 	}
-	return fmt.Errorf("subscription with name '%s' not found", name)
+	return fmt.Errorf("subscription with ID '%s' not found", id)
 }
 
-func (s *MemoryStorage) DeleteSubscription(name string) error {
+func (s *MemoryStorage) DeleteSubscription(id string) error {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
 	for i, sub := range s.subscriptions {
-		if sub.Name() == name {
+		if sub.ID() == id {
 			// Remove the subscription by slicing
 			s.subscriptions = append(s.subscriptions[:i], s.subscriptions[i+1:]...)
 			return nil
 		}
 	}
-	return fmt.Errorf("subscription with name '%s' not found", name)
+	return fmt.Errorf("subscription with ID '%s' not found", id)
+}
+
+// checkVersion rejects updates that change the stored subscription in place,
+// which could not be rolled back, or that are based on an outdated copy
+func checkVersion(stored, updated *models.Subscription) error {
+	if stored == updated {
+		return fmt.Errorf("subscription updates must be made to a copy")
+	}
+	if updated.Version() != stored.Version() {
+		return ErrStale
+	}
+	return nil
 }
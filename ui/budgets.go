@@ -0,0 +1,156 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"subscription-tracker/billing"
+	"subscription-tracker/models"
+
+	"github.com/rivo/tview"
+)
+
+// Label of the budgets field in the settings form
+const fieldBudgets = "Monthly Budgets (Category=amount [CUR], * for overall)"
+
+// showBudgets compares the monthly spending of each budgeted category with
+// its budget
+func (ui *UI) showBudgets() {
+	list := tview.NewList()
+	list.SetBorder(true).SetTitle(" Budgets per Month ").SetTitleAlign(tview.AlignLeft)
+
+	checks, err := ui.budgetChecks()
+	if err != nil {
+		list.AddItem("[red]Some budgets are incomplete[-]", tview.Escape(err.Error()), 0, nil)
+	}
+	if len(checks) == 0 {
+		list.AddItem("No budgets", "Set monthly budgets per category in the settings", 0, nil)
+	}
+	for _, check := range checks {
+		used := 0.0
+		if check.Limit.Amount > 0 {
+			used = float64(check.Spent.Amount) / float64(check.Limit.Amount) * 100
+		}
+		list.AddItem(
+			budgetColor(check.Status, tview.Escape(check.Budget.Label())),
+			fmt.Sprintf("%s of %s (%.0f%%, %s)", check.Spent, check.Limit, used, check.Status), 0, nil)
+	}
+
+	list.AddItem("Edit Budgets", "Change budgets in the settings", 'e', func() {
+		ui.pages.RemovePage("budgets")
+		ui.showSettingsForm()
+	})
+	list.AddItem("Back to Menu", "Return to main menu", 'b', func() {
+		ui.pages.RemovePage("budgets")
+		ui.showMenu()
+	})
+
+	ui.pages.AddPage("budgets", list, true, true)
+}
+
+// budgetChecks compares the stored subscriptions with the configured budgets
+func (ui *UI) budgetChecks() ([]billing.BudgetCheck, error) {
+	settings := ui.settings.GetSettings()
+	return billing.CheckBudgets(ui.storage.GetSubscriptions(), settings.Budgets, settings.ExchangeRates)
+}
+
+// budgetMenuText labels the budgets menu item, counting budgets that are near
+// or over their limit
+func (ui *UI) budgetMenuText() string {
+	checks, _ := ui.budgetChecks()
+	flagged := billing.FlaggedBudgets(checks)
+	if len(flagged) == 0 {
+		return "Budgets"
+	}
+	status := billing.BudgetNear
+	for _, check := range flagged {
+		if check.Status == billing.BudgetOver {
+			status = billing.BudgetOver
+		}
+	}
+	return budgetColor(status, fmt.Sprintf("Budgets (%d)", len(flagged)))
+}
+
+// budgetStatuses maps lowercased category names to the status of their
+// budget, for the categories that are near or over it
+func budgetStatuses(checks []billing.BudgetCheck) map[string]billing.BudgetStatus {
+	statuses := make(map[string]billing.BudgetStatus)
+	for _, check := range billing.FlaggedBudgets(checks) {
+		statuses[strings.ToLower(check.Budget.Category)] = check.Status
+	}
+	return statuses
+}
+
+// budgetColor highlights text in red when over budget and yellow when near it
+func budgetColor(status billing.BudgetStatus, text string) string {
+	switch status {
+	case billing.BudgetOver:
+		return "[red]" + text + "[-]"
+	case billing.BudgetNear:
+		return "[yellow]" + text + "[-]"
+	}
+	return text
+}
+
+// budgetSummary lists the budgets that are near or over their limit
+func budgetSummary(checks []billing.BudgetCheck) string {
+	var parts []string
+	for _, check := range billing.FlaggedBudgets(checks) {
+		parts = append(parts, budgetColor(check.Status, tview.Escape(fmt.Sprintf("%s %s of %s", check.Budget.Label(), check.Spent, check.Limit))))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return "Budgets: " + strings.Join(parts, " | ")
+}
+
+// confirmBudgets runs save right away, or after the user confirms when saving
+// the subscription would bring a budget near or over its limit
+func (ui *UI) confirmBudgets(sub *models.Subscription, save func()) {
+	settings := ui.settings.GetSettings()
+	impact := billing.BudgetImpact(ui.storage.GetSubscriptions(), sub, settings.Budgets, settings.ExchangeRates)
+	if len(impact) == 0 {
+		save()
+		return
+	}
+
+	lines := make([]string, len(impact))
+	for i, check := range impact {
+		lines[i] = check.String()
+	}
+	modal := tview.NewModal().
+		SetText(fmt.Sprintf("Saving '%s' affects these budgets:\n%s\nSave anyway?", sub.Name(), strings.Join(lines, "\n"))).
+		AddButtons([]string{"Save", "Cancel"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			ui.pages.RemovePage("budget")
+			if buttonLabel == "Save" {
+				save()
+			}
+		})
+	ui.pages.AddPage("budget", modal, false, true)
+}
+
+// formatBudgets writes one "Category=amount CUR" line per budget
+func formatBudgets(budgets []models.Budget) string {
+	lines := make([]string, len(budgets))
+	for i, budget := range budgets {
+		lines[i] = models.FormatBudget(budget)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// parseBudgets parses "Category=amount [CUR]" lines, ignoring blank lines
+func parseBudgets(text string, defaultCurrency string) ([]models.Budget, error) {
+	var budgets []models.Budget
+	for i, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		budget, err := models.ParseBudget(line, defaultCurrency)
+		if err != nil {
+			return nil, fmt.Errorf("Budgets line %d: %v", i+1, err)
+		}
+		budgets = append(budgets, budget)
+	}
+	return budgets, nil
+}
@@ -0,0 +1,52 @@
+package ui
+
+import (
+	"subscription-tracker/models"
+
+	"github.com/rivo/tview"
+)
+
+const (
+	fieldFilterCategory = "Category"
+	fieldFilterTag      = "Tag"
+	allCategories       = "All"
+)
+
+// showFilterForm lets the user narrow the list and its totals down to a
+// category and/or tag
+func (ui *UI) showFilterForm() {
+	categories, _ := models.GroupByCategory(ui.storage.GetSubscriptions())
+	options := append([]string{allCategories}, categories...)
+
+	selected := 0
+	for i, category := range categories {
+		if category == ui.filter.Category {
+			selected = i + 1
+		}
+	}
+
+	form := tview.NewForm()
+	form.
+		AddDropDown(fieldFilterCategory, options, selected, nil).
+		AddInputField(fieldFilterTag, ui.filter.Tag, 20, nil, nil).
+		AddButton("Apply", func() {
+			_, category := form.GetFormItemByLabel(fieldFilterCategory).(*tview.DropDown).GetCurrentOption()
+			if category == allCategories {
+				category = ""
+			}
+			ui.filter = models.Filter{Category: category, Tag: formText(form, fieldFilterTag)}
+			ui.pages.RemovePage("filter")
+			ui.showSubscriptions()
+		}).
+		AddButton("Clear", func() {
+			ui.filter = models.Filter{}
+			ui.pages.RemovePage("filter")
+			ui.showSubscriptions()
+		}).
+		AddButton("Cancel", func() {
+			ui.pages.RemovePage("filter")
+		})
+
+	form.SetBorder(true).SetTitle(" Filter Subscriptions ").SetTitleAlign(tview.AlignLeft)
+	ui.pages.AddPage("filter", form, true, true)
+}
@@ -0,0 +1,126 @@
+package ui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"subscription-tracker/billing"
+	"time"
+
+	"github.com/rivo/tview"
+)
+
+const (
+	fieldForecastMonths = "Months Ahead"
+	// defaultForecastMonths is the horizon shown when the page opens
+	defaultForecastMonths = 12
+	// forecastBarWidth is the length of the bar of the most expensive month
+	forecastBarWidth = 30
+	// peakFactor marks months costing this much more than the average
+	peakFactor = 1.5
+)
+
+// showForecast shows the expected spending per month, with the payments of
+// the selected month per day
+func (ui *UI) showForecast() {
+	months := tview.NewList()
+	months.SetBorder(true).SetTitle(" Forecast per Month ").SetTitleAlign(tview.AlignLeft)
+
+	days := tview.NewTextView().SetDynamicColors(true).SetScrollable(true)
+	days.SetBorder(true).SetTitle(" Payments per Day ").SetTitleAlign(tview.AlignLeft)
+
+	form := tview.NewForm().SetHorizontal(true)
+	form.AddInputField(fieldForecastMonths, strconv.Itoa(defaultForecastMonths), 4, tview.InputFieldInteger, nil)
+	form.
+		AddButton("Update", func() {
+			horizon, err := strconv.Atoi(formText(form, fieldForecastMonths))
+			if err != nil || horizon <= 0 || horizon > 120 {
+				ui.showError("Months ahead must be between 1 and 120")
+				return
+			}
+			ui.fillForecast(months, days, horizon)
+			ui.app.SetFocus(months)
+		}).
+		AddButton("Back", func() {
+			ui.pages.RemovePage("forecast")
+			ui.showMenu()
+		})
+
+	ui.fillForecast(months, days, defaultForecastMonths)
+
+	body := tview.NewFlex().
+		AddItem(months, 0, 1, true).
+		AddItem(days, 0, 1, false)
+	page := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(form, 3, 0, false).
+		AddItem(body, 0, 1, true)
+
+	ui.pages.AddPage("forecast", page, true, true)
+}
+
+func (ui *UI) fillForecast(months *tview.List, days *tview.TextView, horizon int) {
+	forecast, err := billing.BuildForecast(ui.storage.GetSubscriptions(), ui.settings.GetSettings(), time.Now(), horizon)
+	totals := forecast.ByMonth()
+
+	var largest, sum int64
+	for _, month := range totals {
+		sum += month.Total.Amount
+		if month.Total.Amount > largest {
+			largest = month.Total.Amount
+		}
+	}
+	average := float64(sum) / float64(len(totals))
+
+	months.Clear()
+	for _, month := range totals {
+		bar := ""
+		if largest > 0 {
+			bar = strings.Repeat("█", int(float64(month.Total.Amount)/float64(largest)*forecastBarWidth))
+		}
+		title := fmt.Sprintf("%-14s %12s  %s", month.Start.Format("January 2006"), month.Total, bar)
+		if float64(month.Total.Amount) > average*peakFactor {
+			title = "[yellow]" + title + " (peak)[-]"
+		}
+		months.AddItem(title, fmt.Sprintf("%d payment(s)", len(month.Events)), 0, nil)
+	}
+
+	summary := fmt.Sprintf("Total over %d month(s): %s", horizon, forecast.Total())
+	if err != nil {
+		summary += fmt.Sprintf(" [red](%s)[-]", tview.Escape(err.Error()))
+	}
+	months.SetTitle(fmt.Sprintf(" Forecast per Month | %s ", summary))
+
+	showDays := func(index int) {
+		if index < 0 || index >= len(totals) {
+			days.SetText("")
+			return
+		}
+		days.SetText(formatForecastDays(forecast, totals[index]))
+		days.ScrollToBeginning()
+	}
+	months.SetChangedFunc(func(index int, mainText, secondaryText string, shortcut rune) {
+		showDays(index)
+	})
+	showDays(months.GetCurrentItem())
+}
+
+// formatForecastDays lists the payments of one month per day
+func formatForecastDays(forecast billing.Forecast, month billing.PeriodTotal) string {
+	if len(month.Events) == 0 {
+		return "No payments expected"
+	}
+
+	monthForecast := billing.Forecast{From: forecast.From, Until: forecast.Until, Base: forecast.Base, Events: month.Events}
+	var lines []string
+	for _, day := range monthForecast.ByDay() {
+		lines = append(lines, fmt.Sprintf("[::b]%s  %s[::-]", day.Start.Format("Mon 2006-01-02"), day.Total))
+		for _, event := range day.Events {
+			amount := event.Amount.String()
+			if event.Amount.Currency != forecast.Base {
+				amount = fmt.Sprintf("%s (≈ %s)", event.Amount, event.Converted)
+			}
+			lines = append(lines, fmt.Sprintf("  %s  %s", tview.Escape(event.Name), amount))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,194 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"subscription-tracker/models"
+	"subscription-tracker/storage"
+	"time"
+
+	"github.com/rivo/tview"
+)
+
+// Labels of the history form fields
+const (
+	fieldImportPayments = "Import Payments From (.csv)"
+	fieldActualDate     = "Actual Amount For (YYYY-MM-DD)"
+	fieldActualAmount   = "Actual Amount"
+)
+
+// showHistory shows the payment ledger of a subscription with yearly totals
+func (ui *UI) showHistory(sub *models.Subscription) {
+	text := tview.NewTextView().SetDynamicColors(true).SetScrollable(true)
+	text.SetText(formatHistory(sub, ui.location()))
+	text.SetBorder(true).SetTitle(fmt.Sprintf(" Payment History: %s ", tview.Escape(sub.Name()))).SetTitleAlign(tview.AlignLeft)
+
+	form := tview.NewForm()
+	formHeight := 5
+	if sub.IsVariable() {
+		// Offer the oldest payment still waiting for its actual amount
+		actualDate := ""
+		if pending := sub.PendingPayments(); len(pending) > 0 {
+			actualDate = pending[0].Date.Format("2006-01-02")
+		}
+		form.
+			AddInputField(fieldActualDate, actualDate, 12, nil, nil).
+			AddInputField(fieldActualAmount, "", 12, tview.InputFieldFloat, nil).
+			AddButton("Save Actual", func() {
+				ui.recordActual(sub, formText(form, fieldActualDate), formText(form, fieldActualAmount))
+			})
+		formHeight += 4
+	}
+	form.
+		AddInputField(fieldImportPayments, "", 40, nil, nil).
+		AddButton("Import", func() {
+			payments, err := storage.ImportPayments(formText(form, fieldImportPayments), sub.Cost().Currency, ui.location())
+			if err != nil {
+				ui.showError(err.Error())
+				return
+			}
+
+			updatedSub := sub.Clone()
+			for _, payment := range payments {
+				if err := updatedSub.ImportPayment(payment); err != nil {
+					ui.showError(err.Error())
+					return
+				}
+			}
+			if err := ui.storage.UpdateSubscription(updatedSub.ID(), updatedSub); err != nil {
+				ui.showError(err.Error())
+				return
+			}
+
+			ui.pages.RemovePage("history")
+			ui.showHistory(updatedSub)
+			ui.showSuccess(fmt.Sprintf("Imported %d payment(s)", len(payments)))
+		}).
+		AddButton("Back", func() {
+			ui.pages.RemovePage("history")
+			ui.showSubscriptions()
+		})
+
+	page := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(text, 0, 1, false).
+		AddItem(form, formHeight, 0, true)
+
+	ui.pages.AddPage("history", page, true, true)
+}
+
+// formatHistory lists the price timeline and yearly totals followed by every
+// payment, newest first. Years are counted in the given time zone, which
+// payment dates are calendar dates in.
+func formatHistory(sub *models.Subscription, loc *time.Location) string {
+	lines := formatPrices(sub)
+
+	payments := sub.Payments()
+	if len(payments) == 0 {
+		return strings.Join(append(lines, "No payments recorded yet"), "\n")
+	}
+
+	lines = append(lines, "[::b]Totals by year[::-]")
+	firstYear, lastYear := payments[0].Date.In(loc).Year(), payments[len(payments)-1].Date.In(loc).Year()
+	for year := lastYear; year >= firstYear; year-- {
+		from := time.Date(year, 1, 1, 0, 0, 0, 0, loc)
+		totals := sub.TotalPaid(from, from.AddDate(1, 0, 0))
+		if len(totals) == 0 {
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("%d: %s", year, joinMoney(totals)))
+	}
+
+	lines = append(lines, "", "[::b]Payments[::-]")
+	for i := len(payments) - 1; i >= 0; i-- {
+		payment := payments[i]
+		line := fmt.Sprintf("%s  %12s  %s",
+			payment.Date.Format("2006-01-02"), payment.Amount, payment.Source)
+		switch {
+		case payment.Pending:
+			line += "  [yellow](estimate, actual amount missing)[-]"
+		case payment.Estimate != (models.Money{}):
+			variance := sub.Variances(payment.Date, payment.Date.Add(time.Nanosecond))
+			if len(variance) > 0 {
+				line += fmt.Sprintf("  (estimated %s, %s)", payment.Estimate, signedMoney(variance[0].Difference))
+			}
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// formatPrices lists scheduled price changes and past ones, newest first,
+// highlighting increases
+func formatPrices(sub *models.Subscription) []string {
+	scheduled, history := sub.ScheduledPrices(), sub.PriceHistory()
+	if len(scheduled) == 0 && len(history) == 0 {
+		return nil
+	}
+
+	lines := []string{"[::b]Price changes[::-]"}
+	for i := len(scheduled) - 1; i >= 0; i-- {
+		lines = append(lines, fmt.Sprintf("%s  %12s  scheduled",
+			scheduled[i].EffectiveDate.Format("2006-01-02"), scheduled[i].Cost))
+	}
+	for i := len(history) - 1; i >= 0; i-- {
+		change := history[i]
+		line := fmt.Sprintf("%s  %12s  was %s",
+			change.EffectiveDate.Format("2006-01-02"), change.Cost, change.Previous)
+		if change.IsIncrease() {
+			line = "[red]" + line + "[-]"
+		}
+		lines = append(lines, line)
+	}
+	return append(lines, "")
+}
+
+func joinMoney(amounts []models.Money) string {
+	parts := make([]string, len(amounts))
+	for i, amount := range amounts {
+		parts[i] = amount.String()
+	}
+	return strings.Join(parts, " + ")
+}
+
+// markPaid records the upcoming payment as paid by hand today
+func (ui *UI) markPaid(sub *models.Subscription) {
+	updatedSub := sub.Clone()
+	if err := updatedSub.ProcessManualPayment(models.Today(time.Now(), ui.location())); err != nil {
+		ui.showError(err.Error())
+		return
+	}
+	if err := ui.storage.UpdateSubscription(updatedSub.ID(), updatedSub); err != nil {
+		ui.showError(err.Error())
+		return
+	}
+	ui.showSubscriptions()
+	ui.showSuccess(fmt.Sprintf("Payment recorded. Next payment on %s", updatedSub.NextPaymentDate().Format("2006-01-02")))
+}
+
+// recordActual enters the actual amount of a variable payment
+func (ui *UI) recordActual(sub *models.Subscription, dateStr, amountStr string) {
+	date, err := models.ParseDate(dateStr, ui.location())
+	if err != nil {
+		ui.showError("Invalid date format. Please use YYYY-MM-DD")
+		return
+	}
+	amount, err := models.ParseMoney(amountStr, sub.Cost().Currency)
+	if err != nil {
+		ui.showError(fmt.Sprintf("Actual amount must be an amount in %s", sub.Cost().Currency))
+		return
+	}
+
+	updatedSub := sub.Clone()
+	if err := updatedSub.RecordActual(date, amount); err != nil {
+		ui.showError(err.Error())
+		return
+	}
+	if err := ui.storage.UpdateSubscription(updatedSub.ID(), updatedSub); err != nil {
+		ui.showError(err.Error())
+		return
+	}
+
+	ui.pages.RemovePage("history")
+	ui.showHistory(updatedSub)
+	ui.showSuccess("Actual amount recorded")
+}
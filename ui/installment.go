@@ -0,0 +1,107 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"subscription-tracker/models"
+	"time"
+
+	"github.com/rivo/tview"
+)
+
+// showAmortization shows the payment schedule of an installment plan with
+// its interest and remaining balance
+func (ui *UI) showAmortization(sub *models.Subscription) {
+	text := tview.NewTextView().SetDynamicColors(true).SetScrollable(true)
+	text.SetText(formatAmortization(sub, ui.settings.GetSettings().Calendar()))
+	text.SetBorder(true).SetTitle(fmt.Sprintf(" Installment Plan: %s ", tview.Escape(sub.Name()))).SetTitleAlign(tview.AlignLeft)
+
+	form := tview.NewForm()
+	if sub.IsActive() {
+		form.AddButton("Pay Off", func() {
+			ui.pages.RemovePage("amortization")
+			ui.showPayOffForm(sub)
+		})
+	}
+	form.AddButton("Back", func() {
+		ui.pages.RemovePage("amortization")
+		ui.showSubscriptions()
+	})
+
+	page := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(text, 0, 1, false).
+		AddItem(form, 3, 0, true)
+
+	ui.pages.AddPage("amortization", page, true, true)
+}
+
+// formatAmortization summarizes the plan followed by every installment,
+// marking the ones already paid
+func formatAmortization(sub *models.Subscription, calendar *models.HolidayCalendar) string {
+	plan, _ := sub.Installment()
+	rows := sub.Amortization()
+
+	var totalInterest models.Money
+	for _, row := range rows {
+		totalInterest.Currency = row.Interest.Currency
+		totalInterest.Amount += row.Interest.Amount
+	}
+
+	lines := []string{
+		fmt.Sprintf("Principal: %s at %s%% APR | Payment: %s %s",
+			plan.Principal, formatAPR(plan.APR), sub.Cost(), sub.PaymentFrequency()),
+		fmt.Sprintf("Remaining balance: %s | Interest paid: %s of %s",
+			sub.RemainingBalance(), sub.InterestPaid(), totalInterest),
+		installmentNote(sub, calendar),
+		"",
+		"[::b]   #  Payment       Interest      Principal     Balance[::-]",
+	}
+
+	made := sub.TotalPayments() - sub.RemainingPayments()
+	if plan.IsPaidOff() {
+		made = plan.PaidOffAfter
+	}
+	for _, row := range rows {
+		line := fmt.Sprintf("%4d  %-12s  %-12s  %-12s  %s",
+			row.Number, row.Payment, row.Interest, row.Principal, row.Balance)
+		switch {
+		case row.Number <= made:
+			line = "[gray]" + line + "  paid[-]"
+		case plan.IsPaidOff():
+			line = "[gray]" + line + "  settled by payoff[-]"
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// showPayOffForm asks for the date the remaining balance is paid
+func (ui *UI) showPayOffForm(sub *models.Subscription) {
+	today := models.Today(time.Now(), ui.location()).Format("2006-01-02")
+	title := fmt.Sprintf(" Pay Off %s (balance %s) ", sub.Name(), sub.RemainingBalance())
+	ui.showLifecycleForm(sub, title, "Paid On (YYYY-MM-DD)", today, false,
+		func(s *models.Subscription, date time.Time) error { return s.PayOff(date) },
+		"Installment plan paid off")
+}
+
+// installmentNote shows the remaining balance and the payoff date of an
+// installment plan
+func installmentNote(sub *models.Subscription, calendar *models.HolidayCalendar) string {
+	plan, ok := sub.Installment()
+	if !ok {
+		return ""
+	}
+	if plan.IsPaidOff() {
+		return "Paid off on " + plan.PaidOffOn.Format("2006-01-02")
+	}
+	payoff := sub.PayoffDate(calendar)
+	if sub.HasEnded() {
+		return "Paid off on " + payoff.Format("2006-01-02")
+	}
+	return fmt.Sprintf("Balance %s of %s, paid off on %s", sub.RemainingBalance(), plan.Principal, payoff.Format("2006-01-02"))
+}
+
+// formatAPR writes a rate without trailing zeros, e.g. "19.99" or "0"
+func formatAPR(apr float64) string {
+	return strings.TrimSuffix(strings.TrimRight(fmt.Sprintf("%.2f", apr), "0"), ".")
+}
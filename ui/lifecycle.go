@@ -0,0 +1,89 @@
+package ui
+
+import (
+	"fmt"
+	"subscription-tracker/models"
+	"time"
+
+	"github.com/rivo/tview"
+)
+
+const fieldLifecycleDate = "Date (YYYY-MM-DD)"
+
+// lifecycleActions returns the context menu buttons for the state changes
+// the subscription allows
+func lifecycleActions(sub *models.Subscription) []string {
+	switch sub.State() {
+	case models.StateActive:
+		return []string{"Pause", "Cancel Subscription"}
+	case models.StatePaused:
+		return []string{"Resume", "Cancel Subscription"}
+	case models.StateCancelled:
+		return []string{"Reactivate"}
+	}
+	return nil
+}
+
+// handleLifecycleAction runs the state change picked in the context menu
+func (ui *UI) handleLifecycleAction(sub *models.Subscription, action string) {
+	switch action {
+	case "Pause":
+		ui.showLifecycleForm(sub, " Pause Subscription ", "Resume On (YYYY-MM-DD, optional)", "", true,
+			func(s *models.Subscription, date time.Time) error { return s.Pause(date) },
+			"Subscription paused")
+	case "Resume":
+		ui.updateLifecycle(sub, func(s *models.Subscription) error { return s.Resume(time.Now()) },
+			"Subscription resumed")
+	case "Cancel Subscription":
+		ui.showLifecycleForm(sub, " Cancel Subscription ", "Access Ends (YYYY-MM-DD)", sub.NextPaymentDate().Format("2006-01-02"), false,
+			func(s *models.Subscription, date time.Time) error { return s.Cancel(date) },
+			"Subscription cancelled. It is kept in the list for history.")
+	case "Reactivate":
+		ui.showLifecycleForm(sub, " Reactivate Subscription ", fieldNextPayment, "", false,
+			func(s *models.Subscription, date time.Time) error { return s.Reactivate(date) },
+			"Subscription reactivated")
+	}
+}
+
+// showLifecycleForm asks for the date a state change needs
+func (ui *UI) showLifecycleForm(sub *models.Subscription, title, label, value string, optional bool, apply func(*models.Subscription, time.Time) error, message string) {
+	form := tview.NewForm()
+	form.
+		AddInputField(label, value, 20, nil, nil).
+		AddButton("Save", func() {
+			var date time.Time
+			if dateStr := formText(form, label); dateStr != "" || !optional {
+				parsed, err := models.ParseDate(dateStr, ui.location())
+				if err != nil {
+					ui.showError("Invalid date format. Please use YYYY-MM-DD")
+					return
+				}
+				date = parsed
+			}
+
+			ui.pages.RemovePage("lifecycle")
+			ui.updateLifecycle(sub, func(s *models.Subscription) error { return apply(s, date) }, message)
+		}).
+		AddButton("Back", func() {
+			ui.pages.RemovePage("lifecycle")
+		})
+
+	form.SetBorder(true).SetTitle(title).SetTitleAlign(tview.AlignLeft)
+	ui.pages.AddPage("lifecycle", form, true, true)
+}
+
+// updateLifecycle applies a state change to a copy of the subscription and
+// saves it
+func (ui *UI) updateLifecycle(sub *models.Subscription, change func(*models.Subscription) error, message string) {
+	updatedSub := sub.Clone()
+	if err := change(updatedSub); err != nil {
+		ui.showError(err.Error())
+		return
+	}
+	if err := ui.storage.UpdateSubscription(updatedSub.ID(), updatedSub); err != nil {
+		ui.showError(err.Error())
+		return
+	}
+	ui.showSubscriptions()
+	ui.showSuccess(fmt.Sprintf("%s: %s", message, updatedSub.Status()))
+}
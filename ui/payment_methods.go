@@ -0,0 +1,241 @@
+package ui
+
+import (
+	"fmt"
+	"subscription-tracker/billing"
+	"subscription-tracker/models"
+	"time"
+
+	"github.com/rivo/tview"
+)
+
+// Labels of the payment method form fields
+const (
+	fieldMethodLabel    = "Label"
+	fieldMethodType     = "Type"
+	fieldMethodLastFour = "Last Four Digits (optional)"
+	fieldMethodExpiry   = "Expiry (MM/YYYY, cards only)"
+	fieldReassignTo     = "Move Subscriptions To"
+	noPaymentMethod     = "(none)"
+)
+
+func (ui *UI) showPaymentMethods() {
+	list := tview.NewList()
+	list.SetBorder(true).SetTitle(" Payment Methods ").SetTitleAlign(tview.AlignLeft)
+
+	now := time.Now()
+	counts := billing.CountByPaymentMethod(ui.storage.GetSubscriptions())
+	for _, method := range ui.settings.GetSettings().PaymentMethods {
+		title := tview.Escape(method.String())
+		if method.ExpiresBefore(now) {
+			title = "[red]" + title + " (expired)[-]"
+		}
+		description := fmt.Sprintf("Type: %s | Used by %d subscription(s)", method.Type, counts[method.ID])
+
+		currentMethod := method
+		list.AddItem(title, description, 0, func() {
+			ui.showPaymentMethodMenu(currentMethod, counts[currentMethod.ID])
+		})
+	}
+
+	list.
+		AddItem("Add Payment Method", "Add a card or account", 'a', func() {
+			ui.showPaymentMethodForm(nil)
+		}).
+		AddItem("Back to Menu", "Return to main menu", 'b', func() {
+			ui.pages.RemovePage("methods")
+			ui.showMenu()
+		})
+
+	ui.pages.RemovePage("methods")
+	ui.pages.AddPage("methods", list, true, true)
+}
+
+func (ui *UI) showPaymentMethodMenu(method models.PaymentMethod, used int) {
+	modal := tview.NewModal().
+		SetText(fmt.Sprintf("Selected: %s\nUsed by %d subscription(s)", method, used)).
+		AddButtons([]string{"Edit", "Reassign", "Delete", "Cancel"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			ui.pages.RemovePage("method-menu")
+			switch buttonLabel {
+			case "Edit":
+				ui.showPaymentMethodForm(&method)
+			case "Reassign":
+				ui.showReassignForm(method)
+			case "Delete":
+				if used > 0 {
+					ui.showError(fmt.Sprintf("%s is used by %d subscription(s). Reassign them first.", method.Label, used))
+					return
+				}
+				ui.savePaymentMethods(func(methods []models.PaymentMethod) []models.PaymentMethod {
+					return removePaymentMethod(methods, method.ID)
+				}, "Payment method deleted")
+			}
+		})
+	ui.pages.AddPage("method-menu", modal, false, true)
+}
+
+// showPaymentMethodForm adds a payment method, or edits it when method is set
+func (ui *UI) showPaymentMethodForm(method *models.PaymentMethod) {
+	label, lastFour, expiry, typeIndex := "", "", "", 0
+	if method != nil {
+		label = method.Label
+		lastFour = method.LastFour
+		expiry = method.FormatExpiry()
+		for i, t := range models.PaymentMethodTypes {
+			if t == method.Type {
+				typeIndex = i
+			}
+		}
+	}
+
+	typeOptions := make([]string, len(models.PaymentMethodTypes))
+	for i, t := range models.PaymentMethodTypes {
+		typeOptions[i] = t.String()
+	}
+
+	form := tview.NewForm()
+	form.
+		AddInputField(fieldMethodLabel, label, 30, nil, nil).
+		AddDropDown(fieldMethodType, typeOptions, typeIndex, nil).
+		AddInputField(fieldMethodLastFour, lastFour, 4, tview.InputFieldInteger, nil).
+		AddInputField(fieldMethodExpiry, expiry, 8, nil, nil).
+		AddButton("Save", func() {
+			updated, err := readPaymentMethodForm(form, method)
+			if err != nil {
+				ui.showError(err.Error())
+				return
+			}
+			ui.pages.RemovePage("method-form")
+			ui.savePaymentMethods(func(methods []models.PaymentMethod) []models.PaymentMethod {
+				return append(removePaymentMethod(methods, updated.ID), updated)
+			}, "Payment method saved")
+		}).
+		AddButton("Cancel", func() {
+			ui.pages.RemovePage("method-form")
+		})
+
+	title := " Add Payment Method "
+	if method != nil {
+		title = " Edit Payment Method "
+	}
+	form.SetBorder(true).SetTitle(title).SetTitleAlign(tview.AlignLeft)
+	ui.pages.AddPage("method-form", form, true, true)
+}
+
+func readPaymentMethodForm(form *tview.Form, existing *models.PaymentMethod) (models.PaymentMethod, error) {
+	typeIndex, _ := form.GetFormItemByLabel(fieldMethodType).(*tview.DropDown).GetCurrentOption()
+	method := models.PaymentMethod{
+		ID:       models.NewID(),
+		Label:    formText(form, fieldMethodLabel),
+		Type:     models.PaymentMethodTypes[typeIndex],
+		LastFour: formText(form, fieldMethodLastFour),
+	}
+	if existing != nil {
+		method.ID = existing.ID
+	}
+
+	month, year, err := models.ParseExpiry(formText(form, fieldMethodExpiry))
+	if err != nil {
+		return models.PaymentMethod{}, err
+	}
+	if year != 0 && !method.Type.IsCard() {
+		return models.PaymentMethod{}, fmt.Errorf("Only cards have an expiry date")
+	}
+	method.ExpiryMonth, method.ExpiryYear = month, year
+
+	if err := method.Validate(); err != nil {
+		return models.PaymentMethod{}, err
+	}
+	return method, nil
+}
+
+// showReassignForm moves every subscription paid with the method to another one
+func (ui *UI) showReassignForm(from models.PaymentMethod) {
+	targets := []models.PaymentMethod{{}}
+	options := []string{noPaymentMethod}
+	for _, method := range ui.settings.GetSettings().PaymentMethods {
+		if method.ID != from.ID {
+			targets = append(targets, method)
+			options = append(options, method.String())
+		}
+	}
+
+	form := tview.NewForm()
+	form.
+		AddDropDown(fieldReassignTo, options, 0, nil).
+		AddButton("Reassign", func() {
+			index, _ := form.GetFormItemByLabel(fieldReassignTo).(*tview.DropDown).GetCurrentOption()
+			to := targets[index]
+			moved, err := billing.ReassignPaymentMethod(ui.storage, from.ID, to.ID)
+			if err != nil {
+				ui.showError(err.Error())
+				return
+			}
+			ui.pages.RemovePage("reassign")
+			ui.showPaymentMethods()
+
+			target := to.String()
+			if to.ID == "" {
+				target = "no payment method"
+			}
+			ui.showSuccess(fmt.Sprintf("Moved %d subscription(s) from %s to %s", moved, from, target))
+		}).
+		AddButton("Cancel", func() {
+			ui.pages.RemovePage("reassign")
+		})
+
+	form.SetBorder(true).SetTitle(fmt.Sprintf(" Reassign %s ", tview.Escape(from.Label))).SetTitleAlign(tview.AlignLeft)
+	ui.pages.AddPage("reassign", form, true, true)
+}
+
+// savePaymentMethods applies the change to a copy of the payment methods and
+// saves the settings
+func (ui *UI) savePaymentMethods(change func([]models.PaymentMethod) []models.PaymentMethod, message string) {
+	settings := ui.settings.GetSettings()
+	settings.PaymentMethods = change(append([]models.PaymentMethod(nil), settings.PaymentMethods...))
+	if err := ui.settings.SaveSettings(settings); err != nil {
+		ui.showError(err.Error())
+		return
+	}
+	ui.showPaymentMethods()
+	ui.showSuccess(message)
+}
+
+func removePaymentMethod(methods []models.PaymentMethod, id string) []models.PaymentMethod {
+	var kept []models.PaymentMethod
+	for _, method := range methods {
+		if method.ID != id {
+			kept = append(kept, method)
+		}
+	}
+	return kept
+}
+
+// paymentMethodOptions returns the dropdown choices for a subscription's
+// payment method and the index of the selected one
+func paymentMethodOptions(methods []models.PaymentMethod, selectedID string) ([]string, int) {
+	options := []string{noPaymentMethod}
+	selected := 0
+	for i, method := range methods {
+		options = append(options, method.String())
+		if method.ID == selectedID {
+			selected = i + 1
+		}
+	}
+	return options, selected
+}
+
+// paymentMethodNote describes the payment method of a subscription and warns
+// when it expires before the next payment
+func paymentMethodNote(sub *models.Subscription, settings models.Settings) string {
+	method, ok := settings.PaymentMethod(sub.PaymentMethodID())
+	if !ok {
+		return ""
+	}
+	note := "Paid with " + tview.Escape(method.String())
+	if _, expired := billing.ExpiredPaymentMethod(sub, settings.PaymentMethods); expired {
+		note = "[red]" + note + ", expires before next payment[-]"
+	}
+	return note
+}
@@ -0,0 +1,44 @@
+package ui
+
+import (
+	"fmt"
+	"subscription-tracker/billing"
+	"subscription-tracker/models"
+	"time"
+)
+
+// applyPromotionEdit starts, changes or ends the promotion. A promotion left
+// as it was keeps counting down its remaining payments.
+func applyPromotionEdit(sub *models.Subscription, input subscriptionInput) error {
+	current, ok := sub.Promotion()
+	switch {
+	case !input.promotion.Price.IsPositive():
+		if ok {
+			sub.ClearPromotion()
+		}
+		return nil
+	case ok && current.Price == input.promotion.Price && current.Payments == input.promotion.Payments &&
+		current.EndDate.Equal(input.promotion.EndDate):
+		return nil
+	default:
+		return sub.SetPromotion(input.promotion)
+	}
+}
+
+// promotionNote describes the running promotion and the regular price that
+// follows it, highlighting a jump that is close
+func promotionNote(sub *models.Subscription, calendar *models.HolidayCalendar) string {
+	promotion, ok := sub.Promotion()
+	if !ok {
+		return ""
+	}
+	date, price, ok := sub.PromotionEnd(calendar)
+	if !ok {
+		return fmt.Sprintf("Promo %s until the last payment", promotion.Price)
+	}
+	note := fmt.Sprintf("Promo %s, then %s from %s", promotion.Price, price, date.Format("2006-01-02"))
+	if !date.After(time.Now().Add(billing.DefaultReminderWindow)) {
+		return "[red]" + note + "[-]"
+	}
+	return "[yellow]" + note + "[-]"
+}
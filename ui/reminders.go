@@ -0,0 +1,37 @@
+package ui
+
+import (
+	"fmt"
+	"subscription-tracker/billing"
+	"time"
+
+	"github.com/rivo/tview"
+)
+
+func (ui *UI) showReminders() {
+	list := tview.NewList()
+	list.SetBorder(true).SetTitle(" Reminders ").SetTitleAlign(tview.AlignLeft)
+
+	reminders := billing.Reminders(ui.storage.GetSubscriptions(), ui.settings.GetSettings(), time.Now(), billing.DefaultReminderWindow)
+	if len(reminders) == 0 {
+		list.AddItem("Nothing coming up", fmt.Sprintf("No reminders in the next %d days", int(billing.DefaultReminderWindow.Hours()/24)), 0, nil)
+	}
+	for _, reminder := range reminders {
+		subscriptionID := reminder.SubscriptionID
+		list.AddItem(tview.Escape(reminder.Name), reminder.Message, 0, func() {
+			sub, err := ui.storage.GetSubscription(subscriptionID)
+			if err != nil {
+				ui.showError(err.Error())
+				return
+			}
+			ui.showSubscriptionMenu(sub)
+		})
+	}
+
+	list.AddItem("Back to Menu", "Return to main menu", 'b', func() {
+		ui.pages.RemovePage("reminders")
+		ui.showMenu()
+	})
+
+	ui.pages.AddPage("reminders", list, true, true)
+}
@@ -0,0 +1,241 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"subscription-tracker/billing"
+	"subscription-tracker/models"
+	"subscription-tracker/storage"
+	"time"
+
+	"github.com/rivo/tview"
+)
+
+// Labels of the settings form fields
+const (
+	fieldBaseCurrency = "Base Currency"
+	fieldRatesDate    = "Rates Date (YYYY-MM-DD)"
+	fieldRates        = "Rates (CODE=value of 1 unit in base)"
+	fieldImportPath   = "Import Rates From (.csv/.json)"
+	fieldCostPeriod   = "Show Costs Per"
+	fieldTimeZone     = "Time Zone (IANA name, blank for local)"
+	fieldHolidays     = "Holidays (YYYY-MM-DD Name per line)"
+	fieldHolidayPath  = "Import Holidays From (.csv/.json)"
+)
+
+func (ui *UI) showSettingsForm() {
+	settings := ui.settings.GetSettings()
+	originalRates := formatRates(settings.ExchangeRates)
+
+	rateDate := ""
+	if !settings.ExchangeRates.Date.IsZero() {
+		rateDate = settings.ExchangeRates.Date.Format("2006-01-02")
+	}
+
+	periodOptions := make([]string, len(models.Periods))
+	periodIndex := 0
+	for i, period := range models.Periods {
+		periodOptions[i] = string(period)
+		if period == settings.CostPeriod {
+			periodIndex = i
+		}
+	}
+
+	form := tview.NewForm()
+	form.
+		AddInputField(fieldBaseCurrency, settings.BaseCurrency, 5, nil, nil).
+		AddDropDown(fieldCostPeriod, periodOptions, periodIndex, nil).
+		AddInputField(fieldTimeZone, settings.TimeZone, 30, nil, nil).
+		AddInputField(fieldRatesDate, rateDate, 12, nil, nil).
+		AddTextArea(fieldRates, originalRates, 40, 6, 0, nil).
+		AddInputField(fieldImportPath, "", 40, nil, nil).
+		AddTextArea(fieldBudgets, formatBudgets(settings.Budgets), 40, 4, 0, nil).
+		AddTextArea(fieldHolidays, formatHolidays(settings.Holidays), 40, 6, 0, nil).
+		AddInputField(fieldHolidayPath, "", 40, nil, nil)
+
+	form.
+		AddButton("Save", func() {
+			updated, err := readSettingsForm(form, settings, originalRates)
+			if err != nil {
+				ui.showError(err.Error())
+				return
+			}
+			// Payment dates keep their days in the new time zone. They are moved
+			// first so the settings only name the new zone once they are in it.
+			moved := updated.TimeZone != settings.TimeZone
+			if moved {
+				if err := billing.MoveToLocation(ui.storage, settings.Location(), updated.Location()); err != nil {
+					ui.showError(err.Error())
+					return
+				}
+			}
+			if err := ui.settings.SaveSettings(updated); err != nil {
+				if moved {
+					if moveErr := billing.MoveToLocation(ui.storage, updated.Location(), settings.Location()); moveErr != nil {
+						err = fmt.Errorf("%v; moving payment dates back failed: %v", err, moveErr)
+					}
+				}
+				ui.showError(err.Error())
+				return
+			}
+			// The list follows the new default period
+			ui.period = ""
+			ui.pages.RemovePage("settings")
+			ui.showSuccess("Settings saved successfully")
+		}).
+		AddButton("Import Rates", func() {
+			base := strings.ToUpper(formText(form, fieldBaseCurrency))
+			rates, err := storage.ImportExchangeRates(formText(form, fieldImportPath), base)
+			if err != nil {
+				ui.showError(err.Error())
+				return
+			}
+
+			// Show the imported rates for review; they are only kept once saved
+			form.GetFormItemByLabel(fieldRates).(*tview.TextArea).SetText(formatRates(rates), false)
+			if !rates.Date.IsZero() {
+				form.GetFormItemByLabel(fieldRatesDate).(*tview.InputField).SetText(rates.Date.Format("2006-01-02"))
+			}
+			ui.showSuccess(fmt.Sprintf("Imported %d rate(s). Review and save to apply.", len(rates.Rates)))
+		}).
+		AddButton("Import Holidays", func() {
+			holidays, err := storage.ImportHolidays(formText(form, fieldHolidayPath))
+			if err != nil {
+				ui.showError(err.Error())
+				return
+			}
+
+			// Show the imported holidays for review; they are only kept once saved
+			form.GetFormItemByLabel(fieldHolidays).(*tview.TextArea).SetText(formatHolidays(holidays), false)
+			ui.showSuccess(fmt.Sprintf("Imported %d holiday(s). Review and save to apply.", len(holidays)))
+		}).
+		AddButton("Cancel", func() {
+			ui.pages.RemovePage("settings")
+		})
+
+	form.SetBorder(true).SetTitle(" Settings ").SetTitleAlign(tview.AlignLeft)
+	ui.pages.AddPage("settings", form, true, true)
+}
+
+// readSettingsForm builds updated settings from the form. When only the base
+// currency changed, the existing rate table is converted to the new base.
+func readSettingsForm(form *tview.Form, current models.Settings, originalRates string) (models.Settings, error) {
+	base := strings.ToUpper(formText(form, fieldBaseCurrency))
+	if !models.ValidCurrency(base) {
+		return models.Settings{}, fmt.Errorf("Unsupported base currency '%s'", base)
+	}
+
+	var date time.Time
+	if dateStr := formText(form, fieldRatesDate); dateStr != "" {
+		parsed, err := time.Parse("2006-01-02", dateStr)
+		if err != nil {
+			return models.Settings{}, fmt.Errorf("Invalid rates date. Please use YYYY-MM-DD")
+		}
+		date = parsed
+	}
+
+	ratesText := form.GetFormItemByLabel(fieldRates).(*tview.TextArea).GetText()
+
+	var rates models.ExchangeRates
+	if base != current.BaseCurrency && strings.TrimSpace(ratesText) == strings.TrimSpace(originalRates) {
+		rebased, err := current.ExchangeRates.Rebase(base)
+		if err != nil {
+			return models.Settings{}, fmt.Errorf("Cannot switch base currency to %s: %v", base, err)
+		}
+		rates = rebased
+		rates.Date = date
+	} else {
+		parsed, err := parseRates(ratesText)
+		if err != nil {
+			return models.Settings{}, err
+		}
+		rates, err = models.NewExchangeRates(base, date, parsed)
+		if err != nil {
+			return models.Settings{}, err
+		}
+	}
+
+	budgets, err := parseBudgets(form.GetFormItemByLabel(fieldBudgets).(*tview.TextArea).GetText(), base)
+	if err != nil {
+		return models.Settings{}, err
+	}
+
+	holidays, err := parseHolidays(form.GetFormItemByLabel(fieldHolidays).(*tview.TextArea).GetText())
+	if err != nil {
+		return models.Settings{}, err
+	}
+
+	periodIndex, _ := form.GetFormItemByLabel(fieldCostPeriod).(*tview.DropDown).GetCurrentOption()
+
+	updated := current
+	updated.CostPeriod = models.Periods[periodIndex]
+	updated.BaseCurrency = base
+	updated.ExchangeRates = rates
+	updated.Budgets = budgets
+	updated.Holidays = holidays
+	updated.TimeZone = formText(form, fieldTimeZone)
+	return updated, nil
+}
+
+// formatRates renders the rate table as one "CODE=rate" line per currency
+func formatRates(rates models.ExchangeRates) string {
+	var lines []string
+	for _, currency := range rates.Currencies() {
+		if currency == rates.Base {
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("%s=%s", currency, strconv.FormatFloat(rates.Rates[currency], 'f', -1, 64)))
+	}
+	sort.Strings(lines)
+	return strings.Join(lines, "\n")
+}
+
+// parseRates parses "CODE=rate" lines, ignoring blank lines
+func parseRates(text string) (map[string]float64, error) {
+	rates := make(map[string]float64)
+	for i, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		currency, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("Line %d: expected CODE=rate", i+1)
+		}
+		rate, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return nil, fmt.Errorf("Line %d: invalid rate '%s'", i+1, strings.TrimSpace(value))
+		}
+		rates[strings.ToUpper(strings.TrimSpace(currency))] = rate
+	}
+	return rates, nil
+}
+
+// formatHolidays writes one "YYYY-MM-DD Name" line per holiday
+func formatHolidays(holidays []models.Holiday) string {
+	lines := make([]string, len(holidays))
+	for i, holiday := range holidays {
+		lines[i] = models.FormatHoliday(holiday)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// parseHolidays parses "YYYY-MM-DD Name" lines, ignoring blank lines
+func parseHolidays(text string) ([]models.Holiday, error) {
+	var holidays []models.Holiday
+	for i, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		holiday, err := models.ParseHoliday(line)
+		if err != nil {
+			return nil, fmt.Errorf("Holidays line %d: %v", i+1, err)
+		}
+		holidays = append(holidays, holiday)
+	}
+	return holidays, nil
+}
@@ -0,0 +1,79 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"subscription-tracker/billing"
+	"subscription-tracker/models"
+
+	"github.com/rivo/tview"
+)
+
+// showShares lists what each member of the shared subscriptions pays per month
+func (ui *UI) showShares() {
+	list := tview.NewList()
+	list.SetBorder(true).SetTitle(" Shared Costs per Month ").SetTitleAlign(tview.AlignLeft)
+
+	members, err := billing.MonthlyShares(ui.storage.GetSubscriptions(), ui.settings.GetSettings().ExchangeRates)
+	if err != nil {
+		list.AddItem("[red]Some subscriptions are left out[-]", tview.Escape(err.Error()), 0, nil)
+	}
+	if len(members) == 0 {
+		list.AddItem("No shared subscriptions", "Add participants to a subscription to split its cost", 0, nil)
+	}
+	for _, member := range members {
+		items := make([]string, len(member.Items))
+		for i, item := range member.Items {
+			items[i] = fmt.Sprintf("%s %s", item.Subscription, item.Monthly)
+		}
+		list.AddItem(
+			fmt.Sprintf("%s: %s / month", tview.Escape(member.Name), member.Monthly),
+			tview.Escape(strings.Join(items, ", ")), 0, nil)
+	}
+
+	list.AddItem("Back to Menu", "Return to main menu", 'b', func() {
+		ui.pages.RemovePage("shares")
+		ui.showMenu()
+	})
+
+	ui.pages.AddPage("shares", list, true, true)
+}
+
+// splitNote lists who shares the subscription and their part of the cost
+func splitNote(sub *models.Subscription) string {
+	participants := sub.Participants()
+	if len(participants) == 0 {
+		return ""
+	}
+	parts := make([]string, len(participants))
+	for i, participant := range participants {
+		parts[i] = participant.String()
+	}
+	return "Shared: " + tview.Escape(strings.Join(parts, ", "))
+}
+
+// formatParticipants writes one "Name=share" line per participant
+func formatParticipants(participants []models.Participant) string {
+	lines := make([]string, len(participants))
+	for i, participant := range participants {
+		lines[i] = models.FormatParticipant(participant)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// parseParticipants parses "Name=share" lines, ignoring blank lines
+func parseParticipants(text string, currency string) ([]models.Participant, error) {
+	var participants []models.Participant
+	for i, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		participant, err := models.ParseParticipant(line, currency)
+		if err != nil {
+			return nil, fmt.Errorf("Participants line %d: %v", i+1, err)
+		}
+		participants = append(participants, participant)
+	}
+	return participants, nil
+}
@@ -4,8 +4,10 @@ import (
 	"fmt"
 	"log"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
+	"subscription-tracker/billing"
 	"subscription-tracker/models"
 	"subscription-tracker/storage"
 	"time"
@@ -13,21 +15,46 @@ import (
 	"github.com/rivo/tview"
 )
 
+// paymentCheckInterval is how often due payments are processed while the UI is open
+const paymentCheckInterval = time.Minute
+
+// recentPriceWindow is how long a price increase is highlighted in the list
+const recentPriceWindow = 90 * 24 * time.Hour
+
 type UI struct {
 	app           *tview.Application
 	pages         *tview.Pages
 	storage       storage.Storage
+	settings      storage.SettingsStorage
+	processor     *billing.Processor
+	menu          *tview.List
 	subscriptions *tview.List
+	summary       *tview.TextView
 	form          *tview.Form
+	filter        models.Filter
+	grouped       bool
+	period        models.Period
+	stop          chan struct{}
 }
 
-func initializeStorage() (storage.Storage, error) {
+func initializeStorage() (storage.Storage, storage.SettingsStorage, error) {
+	// Settings come first since they hold the time zone of payment dates
+	settingsFilePath := filepath.Join("data", "settings.json")
+	settings, err := storage.NewJSONSettingsStorage(settingsFilePath)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	dataFilePath := filepath.Join("data", "subscriptions.json")
-	return storage.NewJSONStorage(dataFilePath)
+	subscriptions, err := storage.NewJSONStorage(dataFilePath, settings.GetSettings().Location())
+	if err != nil {
+		return nil, nil, err
+	}
+	return subscriptions, settings, nil
 }
 
 func NewUI(app *tview.Application) *UI {
-	storage, err := initializeStorage()
+	storage, settings, err := initializeStorage()
 	if err != nil {
 		log.Printf("Failed to initialize storage: %v", err)
 		// Show error modal and provide option to retry or exit
@@ -46,24 +73,62 @@ func NewUI(app *tview.Application) *UI {
 	}
 
 	ui := &UI{
-		app:     app,
-		pages:   tview.NewPages(),
-		storage: storage,
+		app:       app,
+		pages:     tview.NewPages(),
+		storage:   storage,
+		settings:  settings,
+		processor: billing.NewProcessor(storage, settings),
+		stop:      make(chan struct{}),
 	}
 
 	ui.setupPages()
+
+	// Catch up on payments missed while the application was closed
+	ui.showPaymentReport(ui.processor.CatchUp(time.Now()))
 	return ui
 }
 
+// startPaymentProcessor periodically processes due payments until the UI stops
+func (ui *UI) startPaymentProcessor() {
+	ticker := time.NewTicker(paymentCheckInterval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ui.stop:
+				return
+			case now := <-ticker.C:
+				report := ui.processor.CatchUp(now)
+				if len(report.Advances) == 0 && len(report.Errors) == 0 {
+					continue
+				}
+				// The screen belongs to the application's event loop
+				ui.app.QueueUpdateDraw(func() {
+					switch name, _ := ui.pages.GetFrontPage(); name {
+					case "list":
+						ui.showSubscriptions()
+					case "menu":
+						ui.buildMenu()
+					}
+					ui.showPaymentReport(report)
+				})
+			}
+		}
+	}()
+}
+
+// showPaymentReport shows what a catch-up run did, if anything
+func (ui *UI) showPaymentReport(report billing.Report) {
+	if len(report.Advances) > 0 || len(report.Errors) > 0 {
+		ui.showSuccess(report.Summary())
+	}
+}
+
 func (ui *UI) setupPages() {
 	// Create main menu
-	menu := tview.NewList().
-		AddItem("Add Subscription", "Add a new subscription", 'a', ui.showAddForm).
-		AddItem("List Subscriptions", "View all subscriptions", 'l', ui.showSubscriptions).
-		AddItem("Quit", "Exit the application", 'q', func() {
-			ui.app.Stop()
-		})
-	menu.SetBorder(true).SetTitle(" Main Menu ").SetTitleAlign(tview.AlignLeft)
+	ui.menu = tview.NewList()
+	ui.menu.SetBorder(true).SetTitle(" Main Menu ").SetTitleAlign(tview.AlignLeft)
+	ui.buildMenu()
 
 	// Create subscription form
 	ui.form = tview.NewForm()
@@ -71,135 +136,826 @@ func (ui *UI) setupPages() {
 
 	// Create subscriptions list
 	ui.subscriptions = tview.NewList().
-		AddItem("Back to Menu", "Return to main menu", 'b', func() {
-			ui.pages.SwitchToPage("menu")
-		})
-	ui.subscriptions.SetBorder(true).SetTitle(" Active Subscriptions ").SetTitleAlign(tview.AlignLeft)
+		AddItem("Back to Menu", "Return to main menu", 'b', ui.showMenu)
+	ui.subscriptions.SetBorder(true).SetTitle(" Subscriptions ").SetTitleAlign(tview.AlignLeft)
+
+	// Create totals summary shown below the list
+	ui.summary = tview.NewTextView().SetDynamicColors(true)
+	ui.summary.SetBorder(true).SetTitle(" Totals ").SetTitleAlign(tview.AlignLeft)
+
+	listPage := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(ui.subscriptions, 0, 1, true).
+		AddItem(ui.summary, 5, 0, false)
 
 	// Add pages
-	ui.pages.AddPage("menu", menu, true, true)
+	ui.pages.AddPage("menu", ui.menu, true, true)
 	ui.pages.AddPage("form", ui.form, true, false)
-	ui.pages.AddPage("list", ui.subscriptions, true, false)
+	ui.pages.AddPage("list", listPage, true, false)
+}
+
+// buildMenu fills the main menu, including counts that change over time
+func (ui *UI) buildMenu() {
+	current := ui.menu.GetCurrentItem()
+	reminders := billing.Reminders(ui.storage.GetSubscriptions(), ui.settings.GetSettings(), time.Now(), billing.DefaultReminderWindow)
+
+	remindersText := "Reminders"
+	if len(reminders) > 0 {
+		remindersText = fmt.Sprintf("[yellow]Reminders (%d)[-]", len(reminders))
+	}
+
+	ui.menu.Clear().
+		AddItem("Add Subscription", "Add a new subscription", 'a', ui.showAddForm).
+		AddItem("List Subscriptions", "View all subscriptions", 'l', ui.showSubscriptions).
+		AddItem("Forecast", "Expected spending over the coming months", 'f', ui.showForecast).
+		AddItem(remindersText, "Trial endings, cancel-by deadlines and expiring cards", 'r', ui.showReminders).
+		AddItem("Shared Costs", "What each member of shared subscriptions pays per month", 'c', ui.showShares).
+		AddItem(ui.budgetMenuText(), "Monthly spending against category budgets", 'b', ui.showBudgets).
+		AddItem("Estimate vs Actual", "How variable subscriptions compare with their estimates", 'v', ui.showVariance).
+		AddItem("Payment Methods", "Cards and accounts subscriptions are paid with", 'p', ui.showPaymentMethods).
+		AddItem("Settings", "Base currency, exchange rates, cost period and budgets", 's', ui.showSettingsForm).
+		AddItem("Quit", "Exit the application", 'q', func() {
+			ui.app.Stop()
+		})
+	ui.menu.SetCurrentItem(current)
+}
+
+// showMenu refreshes and switches to the main menu
+func (ui *UI) showMenu() {
+	ui.buildMenu()
+	ui.pages.SwitchToPage("menu")
+}
+
+// Labels of the subscription form fields, shared by the add and edit forms
+const (
+	fieldName          = "Name"
+	fieldCategory      = "Category (optional)"
+	fieldTags          = "Tags (comma separated)"
+	fieldPaymentMethod = "Payment Method"
+	fieldParticipants  = "Shared With (Name=25% or Name=4.99 per line)"
+	fieldCost          = "Cost"
+	fieldVariable      = "Variable Cost (cost is an estimate)"
+	fieldCurrency      = "Currency (ISO 4217)"
+	fieldFrequencyUnit = "Billing Unit"
+	fieldInterval      = "Billed Every (N units)"
+	fieldRecurrence    = "Recurrence Rule (optional RRULE)"
+	fieldNextPayment   = "Next Payment Date (YYYY-MM-DD)"
+	fieldTrialEnd      = "Free Trial Ends (YYYY-MM-DD, optional)"
+	fieldAnchorDay     = "Billing Day of Month (optional)"
+	fieldRoll          = "On Weekends and Holidays"
+	fieldTotalPayments = "Total Payments (blank renews indefinitely)"
+	fieldNoticeDays    = "Notice Period (days before renewal, optional)"
+	fieldPrincipal     = "Installment Principal (optional, sets the cost)"
+	fieldAPR           = "Installment APR %"
+	fieldNewPrice      = "Scheduled Price (optional)"
+	fieldPriceDate     = "Price Effective From (YYYY-MM-DD)"
+	fieldPromoPrice    = "Promotional Price (optional)"
+	fieldPromoPayments = "Promotional Payments Left (optional)"
+	fieldPromoEnd      = "Promotion Ends (YYYY-MM-DD, optional)"
+)
+
+// subscriptionInput holds validated values entered in a subscription form
+type subscriptionInput struct {
+	name          string
+	category      string
+	tags          []string
+	paymentMethod string
+	participants  []models.Participant
+	cost          models.Money
+	variable      bool
+	frequency     models.Frequency
+	recurrence    string
+	nextPayment   time.Time
+	trialEnd      time.Time
+	anchorDay     int
+	roll          models.RollConvention
+	totalPayments int
+	noticeDays    int
+	principal     models.Money
+	apr           float64
+	newPrice      models.Money
+	priceDate     time.Time
+	promotion     models.Promotion
 }
 
 func (ui *UI) setupForm(form *tview.Form, title string, saveFunc func()) {
 	form.Clear(true)
+	addSubscriptionFields(form, nil, ui.settings.GetSettings().PaymentMethods)
 	form.
-		AddInputField("Name", "", 30, nil, nil).
-		AddInputField("Cost", "", 20, tview.InputFieldFloat, nil).
-		AddInputField("Payment Frequency (daily/weekly/monthly/yearly)", "", 20, nil, nil).
-		AddInputField("Next Payment Date (YYYY-MM-DD)", "", 20, nil, nil).
-		AddInputField("Total Payments", "", 10, tview.InputFieldInteger, nil).
 		AddButton("Save", saveFunc).
-		AddButton("Cancel", func() {
-			ui.pages.SwitchToPage("menu")
-		})
+		AddButton("Cancel", ui.showMenu)
 	if title != "" {
 		form.SetBorder(true).SetTitle(title).SetTitleAlign(tview.AlignLeft)
 	}
 }
 
+// addSubscriptionFields adds the subscription input fields to the form,
+// prefilled from sub when editing an existing subscription
+func addSubscriptionFields(form *tview.Form, sub *models.Subscription, methods []models.PaymentMethod) {
+	name, cost, currency, recurrence, nextPayment, trialEnd, anchorDay, totalPayments := "", "", models.DefaultCurrency, "", "", "", "", ""
+	category, tags, newPrice, priceDate, paymentMethodID, noticeDays, participants := "", "", "", "", "", "", ""
+	principal, apr := "", ""
+	promoPrice, promoPayments, promoEnd := "", "", ""
+	frequency := models.FrequencyMonthly
+	roll := models.RollNone
+	variable := false
+	if sub != nil {
+		name = sub.Name()
+		category = sub.Category()
+		tags = strings.Join(sub.Tags(), ", ")
+		paymentMethodID = sub.PaymentMethodID()
+		participants = formatParticipants(sub.Participants())
+		if days := sub.NoticeDays(); days > 0 {
+			noticeDays = strconv.Itoa(days)
+		}
+		cost = sub.Cost().FormatAmount()
+		currency = sub.Cost().Currency
+		variable = sub.IsVariable()
+		frequency = sub.PaymentFrequency()
+		recurrence = sub.Recurrence()
+		nextPayment = sub.NextPaymentDate().Format("2006-01-02")
+		anchorDay = strconv.Itoa(sub.AnchorDay())
+		roll = sub.RollConvention()
+		if sub.InTrial() {
+			trialEnd = sub.TrialEndDate().Format("2006-01-02")
+		}
+		if !sub.IsOpenEnded() {
+			totalPayments = fmt.Sprintf("%d", sub.TotalPayments())
+		}
+		if plan, ok := sub.Installment(); ok {
+			principal = plan.Principal.FormatAmount()
+			apr = strconv.FormatFloat(plan.APR, 'f', -1, 64)
+		}
+		if scheduled := sub.ScheduledPrices(); len(scheduled) > 0 {
+			newPrice = scheduled[0].Cost.FormatAmount()
+			priceDate = scheduled[0].EffectiveDate.Format("2006-01-02")
+		}
+		if promotion, ok := sub.Promotion(); ok {
+			promoPrice = promotion.Price.FormatAmount()
+			if promotion.Payments > 0 {
+				promoPayments = strconv.Itoa(promotion.Payments)
+			}
+			if !promotion.EndDate.IsZero() {
+				promoEnd = promotion.EndDate.Format("2006-01-02")
+			}
+		}
+	}
+
+	methodOptions, methodIndex := paymentMethodOptions(methods, paymentMethodID)
+	form.
+		AddInputField(fieldName, name, 30, nil, nil).
+		AddInputField(fieldCategory, category, 30, nil, nil).
+		AddInputField(fieldTags, tags, 40, nil, nil).
+		AddDropDown(fieldPaymentMethod, methodOptions, methodIndex, nil).
+		AddInputField(fieldCost, cost, 20, tview.InputFieldFloat, nil).
+		AddInputField(fieldCurrency, currency, 5, nil, nil).
+		AddCheckbox(fieldVariable, variable, nil).
+		AddDropDown(fieldFrequencyUnit, frequencyUnitOptions(), unitIndex(frequency.Unit), nil).
+		AddInputField(fieldInterval, strconv.Itoa(frequency.Interval), 5, tview.InputFieldInteger, nil).
+		AddInputField(fieldRecurrence, recurrence, 50, nil, nil).
+		AddInputField(fieldNextPayment, nextPayment, 20, nil, nil).
+		AddInputField(fieldTrialEnd, trialEnd, 20, nil, nil).
+		AddInputField(fieldAnchorDay, anchorDay, 5, tview.InputFieldInteger, nil).
+		AddDropDown(fieldRoll, rollOptions(), rollIndex(roll), nil).
+		AddInputField(fieldTotalPayments, totalPayments, 10, tview.InputFieldInteger, nil).
+		AddInputField(fieldNoticeDays, noticeDays, 5, tview.InputFieldInteger, nil).
+		AddTextArea(fieldParticipants, participants, 40, 3, 0, nil).
+		AddInputField(fieldPrincipal, principal, 20, tview.InputFieldFloat, nil).
+		AddInputField(fieldAPR, apr, 8, tview.InputFieldFloat, nil).
+		AddInputField(fieldNewPrice, newPrice, 20, tview.InputFieldFloat, nil).
+		AddInputField(fieldPriceDate, priceDate, 20, nil, nil).
+		AddInputField(fieldPromoPrice, promoPrice, 20, tview.InputFieldFloat, nil).
+		AddInputField(fieldPromoPayments, promoPayments, 5, tview.InputFieldInteger, nil).
+		AddInputField(fieldPromoEnd, promoEnd, 20, nil, nil)
+}
+
+// frequencyUnitOptions returns the billing unit choices in display order
+func frequencyUnitOptions() []string {
+	options := make([]string, len(models.FrequencyUnits))
+	for i, unit := range models.FrequencyUnits {
+		options[i] = string(unit) + "s"
+	}
+	return options
+}
+
+// rollOptions returns the roll convention choices in display order
+func rollOptions() []string {
+	options := make([]string, len(models.RollConventions))
+	for i, convention := range models.RollConventions {
+		switch convention {
+		case models.RollNone:
+			options[i] = "keep the date"
+		default:
+			options[i] = "move to " + convention.String() + " business day"
+		}
+	}
+	return options
+}
+
+func rollIndex(convention models.RollConvention) int {
+	for i, c := range models.RollConventions {
+		if c == convention {
+			return i
+		}
+	}
+	return 0
+}
+
+func unitIndex(unit models.FrequencyUnit) int {
+	for i, u := range models.FrequencyUnits {
+		if u == unit {
+			return i
+		}
+	}
+	return 0
+}
+
+// formText returns the text of the input field with the given label
+func formText(form *tview.Form, label string) string {
+	field, ok := form.GetFormItemByLabel(label).(*tview.InputField)
+	if !ok {
+		return ""
+	}
+	return strings.TrimSpace(field.GetText())
+}
+
 func (ui *UI) showAddForm() {
 	ui.setupForm(ui.form, " Add Subscription ", ui.saveSubscription)
 	ui.pages.SwitchToPage("form")
 }
 
-func (ui *UI) validateFormInput(name, costStr, frequency, dateStr, totalPaymentsStr string) (float64, time.Time, int, error) {
+func (ui *UI) validateFormInput(form *tview.Form) (subscriptionInput, error) {
 	var validationErrors []string
 
-	if name == "" {
+	loc := ui.location()
+	input := subscriptionInput{
+		name:     formText(form, fieldName),
+		category: formText(form, fieldCategory),
+		tags:     models.ParseTags(formText(form, fieldTags)),
+	}
+
+	// The first option is no payment method
+	if methodIndex, _ := form.GetFormItemByLabel(fieldPaymentMethod).(*tview.DropDown).GetCurrentOption(); methodIndex > 0 {
+		methods := ui.settings.GetSettings().PaymentMethods
+		if methodIndex > len(methods) {
+			validationErrors = append(validationErrors, "The selected payment method no longer exists")
+		} else {
+			input.paymentMethod = methods[methodIndex-1].ID
+		}
+	}
+
+	if input.name == "" {
 		validationErrors = append(validationErrors, "Name cannot be empty")
 	}
 
-	cost, err := strconv.ParseFloat(costStr, 64)
-	if err != nil || cost <= 0 {
-		validationErrors = append(validationErrors, "Cost must be a positive number")
+	currency := strings.ToUpper(formText(form, fieldCurrency))
+	if !models.ValidCurrency(currency) {
+		validationErrors = append(validationErrors, fmt.Sprintf("Unsupported currency '%s'", currency))
+	} else {
+		// The cost of an installment plan follows from its principal
+		if principalStr := formText(form, fieldPrincipal); principalStr != "" {
+			principal, err := models.ParseMoney(principalStr, currency)
+			if err != nil || !principal.IsPositive() {
+				validationErrors = append(validationErrors, fmt.Sprintf("Installment principal must be a positive amount in %s", currency))
+			}
+			input.principal = principal
+			if aprStr := formText(form, fieldAPR); aprStr != "" {
+				apr, err := strconv.ParseFloat(aprStr, 64)
+				if err != nil {
+					validationErrors = append(validationErrors, "APR must be a percentage such as 19.99")
+				}
+				input.apr = apr
+			}
+		} else {
+			cost, err := models.ParseMoney(formText(form, fieldCost), currency)
+			if err != nil || !cost.IsPositive() {
+				validationErrors = append(validationErrors, fmt.Sprintf("Cost must be a positive amount in %s", currency))
+			}
+			input.cost = cost
+		}
+
+		participants, err := parseParticipants(form.GetFormItemByLabel(fieldParticipants).(*tview.TextArea).GetText(), currency)
+		if err != nil {
+			validationErrors = append(validationErrors, err.Error())
+		}
+		input.participants = participants
+	}
+
+	input.variable = form.GetFormItemByLabel(fieldVariable).(*tview.Checkbox).IsChecked()
+
+	unitOption, _ := form.GetFormItemByLabel(fieldFrequencyUnit).(*tview.DropDown).GetCurrentOption()
+	interval, err := strconv.Atoi(formText(form, fieldInterval))
+	if err != nil || interval <= 0 || unitOption < 0 {
+		validationErrors = append(validationErrors, "Billing interval must be a positive number")
+	} else {
+		input.frequency = models.Frequency{Unit: models.FrequencyUnits[unitOption], Interval: interval}
 	}
 
-	if !models.ValidFrequencies[frequency] {
-		validationErrors = append(validationErrors, "Invalid payment frequency: must be daily, weekly, monthly, or yearly")
+	input.recurrence = models.TrimRRulePrefix(formText(form, fieldRecurrence))
+	var rule *models.RRule
+	if input.recurrence != "" {
+		if rule, err = models.ParseRRule(input.recurrence); err != nil {
+			validationErrors = append(validationErrors, fmt.Sprintf("Invalid recurrence rule: %v", err))
+		}
 	}
 
-	nextPayment, err := time.Parse("2006-01-02", dateStr)
-	if err != nil {
-		validationErrors = append(validationErrors, "Invalid date format. Please use YYYY-MM-DD")
+	if trialStr := formText(form, fieldTrialEnd); trialStr != "" {
+		trialEnd, err := models.ParseDate(trialStr, loc)
+		if err != nil {
+			validationErrors = append(validationErrors, "Invalid trial end date. Please use YYYY-MM-DD")
+		}
+		input.trialEnd = trialEnd
+	}
+
+	// The first charge of a trial is usually on the trial end date, so the
+	// next payment date may be left blank
+	nextPaymentStr := formText(form, fieldNextPayment)
+	var nextPayment time.Time
+	if nextPaymentStr == "" && !input.trialEnd.IsZero() {
+		nextPayment = input.trialEnd
+	} else {
+		parsed, err := models.ParseDate(nextPaymentStr, loc)
+		if err != nil {
+			validationErrors = append(validationErrors, "Invalid date format. Please use YYYY-MM-DD")
+		} else if parsed.Before(input.trialEnd) {
+			validationErrors = append(validationErrors, "Next payment date cannot fall within the free trial")
+		}
+		nextPayment = parsed
+	}
+	input.nextPayment = nextPayment
+
+	// The next payment starts the recurrence and is its first occurrence
+	if rule != nil && !nextPayment.IsZero() {
+		if first, ok := rule.From(nextPayment, nextPayment); ok && !first.Equal(nextPayment) {
+			validationErrors = append(validationErrors, fmt.Sprintf(
+				"Next payment date must be an occurrence of the recurrence rule, such as %s", first.Format("2006-01-02")))
+		}
+	}
+
+	input.anchorDay = nextPayment.Day()
+	if anchorStr := formText(form, fieldAnchorDay); anchorStr != "" {
+		anchorDay, err := strconv.Atoi(anchorStr)
+		if err != nil || anchorDay < 1 || anchorDay > 31 {
+			validationErrors = append(validationErrors, "Billing day must be between 1 and 31")
+		}
+		input.anchorDay = anchorDay
+	}
+
+	if rollOption, _ := form.GetFormItemByLabel(fieldRoll).(*tview.DropDown).GetCurrentOption(); rollOption > 0 {
+		input.roll = models.RollConventions[rollOption]
+	}
+
+	input.totalPayments = models.OpenEnded
+	if totalStr := formText(form, fieldTotalPayments); totalStr != "" {
+		totalPayments, err := strconv.Atoi(totalStr)
+		if err != nil || totalPayments <= 0 {
+			validationErrors = append(validationErrors, "Total payments must be a positive number, or blank for an open-ended subscription")
+		}
+		input.totalPayments = totalPayments
+	}
+
+	if input.principal.IsPositive() && input.frequency.Interval > 0 {
+		payment, err := models.InstallmentPayment(input.principal, input.apr, input.frequency, input.totalPayments)
+		if err != nil {
+			validationErrors = append(validationErrors, fmt.Sprintf("Installment plan: %v", err))
+		}
+		input.cost = payment
+	}
+
+	if noticeStr := formText(form, fieldNoticeDays); noticeStr != "" {
+		noticeDays, err := strconv.Atoi(noticeStr)
+		if err != nil || noticeDays < 0 {
+			validationErrors = append(validationErrors, "Notice period must be a number of days, or blank for none")
+		}
+		input.noticeDays = noticeDays
 	}
 
-	totalPayments, err := strconv.Atoi(totalPaymentsStr)
-	if err != nil || totalPayments <= 0 {
-		validationErrors = append(validationErrors, "Total payments must be a positive number")
+	// A price change needs both a price and the date it takes effect
+	newPriceStr, priceDateStr := formText(form, fieldNewPrice), formText(form, fieldPriceDate)
+	if (newPriceStr == "") != (priceDateStr == "") {
+		validationErrors = append(validationErrors, "A scheduled price needs both a price and an effective date")
+	} else if newPriceStr != "" {
+		newPrice, err := models.ParseMoney(newPriceStr, currency)
+		if err != nil || !newPrice.IsPositive() {
+			validationErrors = append(validationErrors, fmt.Sprintf("Scheduled price must be a positive amount in %s", currency))
+		}
+		priceDate, err := models.ParseDate(priceDateStr, loc)
+		if err != nil {
+			validationErrors = append(validationErrors, "Invalid price effective date. Please use YYYY-MM-DD")
+		}
+		input.newPrice = newPrice
+		input.priceDate = priceDate
+	}
+
+	// A promotion ends after a number of payments, on a date, or both
+	promoPriceStr, promoPaymentsStr, promoEndStr := formText(form, fieldPromoPrice), formText(form, fieldPromoPayments), formText(form, fieldPromoEnd)
+	if promoPriceStr == "" && (promoPaymentsStr != "" || promoEndStr != "") {
+		validationErrors = append(validationErrors, "A promotion needs a promotional price")
+	} else if promoPriceStr != "" {
+		if promoPaymentsStr == "" && promoEndStr == "" {
+			validationErrors = append(validationErrors, "A promotion needs a number of payments or an end date")
+		}
+		promoPrice, err := models.ParseMoney(promoPriceStr, currency)
+		if err != nil || !promoPrice.IsPositive() {
+			validationErrors = append(validationErrors, fmt.Sprintf("Promotional price must be a positive amount in %s", currency))
+		}
+		input.promotion.Price = promoPrice
+		if promoPaymentsStr != "" {
+			payments, err := strconv.Atoi(promoPaymentsStr)
+			if err != nil || payments <= 0 {
+				validationErrors = append(validationErrors, "Promotional payments must be a positive number")
+			}
+			input.promotion.Payments = payments
+		}
+		if promoEndStr != "" {
+			promoEnd, err := models.ParseDate(promoEndStr, loc)
+			if err != nil {
+				validationErrors = append(validationErrors, "Invalid promotion end date. Please use YYYY-MM-DD")
+			}
+			input.promotion.EndDate = promoEnd
+		}
 	}
 
 	if len(validationErrors) > 0 {
-		return 0, time.Time{}, 0, fmt.Errorf(strings.Join(validationErrors, "\n"))
+		return subscriptionInput{}, fmt.Errorf("%s", strings.Join(validationErrors, "\n"))
 	}
 
-	return cost, nextPayment, totalPayments, nil
+	return input, nil
 }
 
 func (ui *UI) saveSubscription() {
-	name := ui.form.GetFormItem(0).(*tview.InputField).GetText()
-	costStr := ui.form.GetFormItem(1).(*tview.InputField).GetText()
-	frequency := ui.form.GetFormItem(2).(*tview.InputField).GetText()
-	dateStr := ui.form.GetFormItem(3).(*tview.InputField).GetText()
-	totalPaymentsStr := ui.form.GetFormItem(4).(*tview.InputField).GetText()
-
-	cost, nextPayment, totalPayments, err := ui.validateFormInput(name, costStr, frequency, dateStr, totalPaymentsStr)
+	input, err := ui.validateFormInput(ui.form)
 	if err != nil {
 		ui.showError(err.Error())
 		return
 	}
 
-	sub, err := models.NewSubscription(name, cost, frequency, nextPayment, totalPayments)
+	sub, err := models.NewSubscription(input.name, input.cost, input.frequency, input.nextPayment, input.totalPayments)
 	if err != nil {
 		ui.showError(err.Error())
 		return
 	}
-
-	if err := ui.storage.AddSubscription(sub); err != nil {
+	if err := sub.SetCategory(input.category); err != nil {
+		ui.showError(err.Error())
+		return
+	}
+	if err := sub.SetVariable(input.variable); err != nil {
+		ui.showError(err.Error())
+		return
+	}
+	if err := sub.SetTags(input.tags); err != nil {
+		ui.showError(err.Error())
+		return
+	}
+	if err := sub.SetPaymentMethodID(input.paymentMethod); err != nil {
+		ui.showError(err.Error())
+		return
+	}
+	if err := sub.SetNoticeDays(input.noticeDays); err != nil {
 		ui.showError(err.Error())
 		return
 	}
+	if err := sub.SetParticipants(input.participants); err != nil {
+		ui.showError(err.Error())
+		return
+	}
+	if err := sub.SetRecurrence(input.recurrence, input.nextPayment); err != nil {
+		ui.showError(err.Error())
+		return
+	}
+	if err := sub.SetRollConvention(input.roll); err != nil {
+		ui.showError(err.Error())
+		return
+	}
+	if input.recurrence == "" {
+		if err := sub.SetAnchorDay(input.anchorDay); err != nil {
+			ui.showError(err.Error())
+			return
+		}
+	}
+	if err := sub.SetTrial(input.trialEnd); err != nil {
+		ui.showError(err.Error())
+		return
+	}
+	if input.principal.IsPositive() {
+		if err := sub.SetInstallment(input.principal, input.apr); err != nil {
+			ui.showError(err.Error())
+			return
+		}
+	}
+	if !input.priceDate.IsZero() {
+		if err := sub.SchedulePriceChange(input.newPrice, input.priceDate); err != nil {
+			ui.showError(err.Error())
+			return
+		}
+	}
+	if input.promotion.Price.IsPositive() {
+		if err := sub.SetPromotion(input.promotion); err != nil {
+			ui.showError(err.Error())
+			return
+		}
+	}
 
-	ui.showSuccess("Subscription added successfully")
-	ui.pages.SwitchToPage("menu")
+	ui.confirmBudgets(sub, func() {
+		if err := ui.storage.AddSubscription(sub); err != nil {
+			ui.showError(err.Error())
+			return
+		}
+
+		ui.showSuccess("Subscription added successfully")
+		ui.showMenu()
+	})
 }
 
 func (ui *UI) showSubscriptions() {
 	ui.subscriptions.Clear()
 
-	subs := ui.storage.GetSubscriptions()
-	for _, sub := range subs {
-		timeLeft := sub.FormattedTimeUntilNextPayment()
-		description := fmt.Sprintf("Cost: $%.2f | Frequency: %s | Next Payment: %s (%s) | %s",
-			sub.Cost(),
-			sub.PaymentFrequency(),
-			sub.NextPaymentDate().Format("2006-01-02"),
-			timeLeft,
-			sub.Status())
-
-		// Create a copy of sub for the closure
-		currentSub := sub
-		ui.subscriptions.AddItem(sub.Name(), description, 0, func() {
-			ui.showSubscriptionMenu(currentSub)
-		})
+	settings := ui.settings.GetSettings()
+	rates := settings.ExchangeRates
+	// Budgets always count every subscription, whatever the filter
+	checks, _ := ui.budgetChecks()
+	statuses := budgetStatuses(checks)
+	subs := models.FilterSubscriptions(ui.storage.GetSubscriptions(), ui.filter)
+	if ui.grouped {
+		categories, groups := models.GroupByCategory(subs)
+		for _, category := range categories {
+			totals, _ := formatTotals(groups[category], rates)
+			// Group headers only label the subscriptions below them
+			header := budgetColor(statuses[strings.ToLower(category)], fmt.Sprintf("[::b]%s (%d)[::-]", tview.Escape(category), len(groups[category])))
+			ui.subscriptions.AddItem(header, totals, 0, nil)
+			for _, sub := range groups[category] {
+				ui.addSubscriptionItem(sub, settings, statuses)
+			}
+		}
+	} else {
+		for _, sub := range subs {
+			ui.addSubscriptionItem(sub, settings, statuses)
+		}
 	}
 
-	ui.subscriptions.AddItem("Back to Menu", "Return to main menu", 'b', func() {
-		ui.pages.SwitchToPage("menu")
-	})
+	period := ui.costPeriod()
+	groupText := "Group by Category"
+	if ui.grouped {
+		groupText = "Ungroup"
+	}
+	ui.subscriptions.
+		AddItem("Filter", fmt.Sprintf("Filter by category or tag (current: %s)", ui.filter), 'f', ui.showFilterForm).
+		AddItem(groupText, "Toggle grouping subscriptions and totals by category", 'g', func() {
+			ui.grouped = !ui.grouped
+			ui.showSubscriptions()
+		}).
+		AddItem(fmt.Sprintf("Show Costs per %s", nextPeriod(period)), fmt.Sprintf("Costs are currently shown per %s", period), 'v', func() {
+			ui.period = nextPeriod(period)
+			ui.showSubscriptions()
+		}).
+		AddItem("Back to Menu", "Return to main menu", 'b', ui.showMenu)
 
+	title := " Subscriptions "
+	if !ui.filter.IsEmpty() {
+		title = fmt.Sprintf(" Subscriptions (%s) ", tview.Escape(ui.filter.String()))
+	}
+	ui.subscriptions.SetTitle(title)
+
+	ui.updateSummary(subs, rates, checks)
 	ui.pages.SwitchToPage("list")
 }
 
+func (ui *UI) addSubscriptionItem(sub *models.Subscription, settings models.Settings, budgets map[string]billing.BudgetStatus) {
+	calendar := settings.Calendar()
+	timeLeft := models.FormatCountdown(models.DaysUntil(time.Now(), sub.AdjustedPaymentDate(calendar), settings.Location()))
+	costLabel := "Cost"
+	if sub.IsVariable() {
+		costLabel = "Estimated Cost"
+	}
+	description := fmt.Sprintf("%s: %s (≈ %s/%s) | Schedule: %s | Next Payment: %s (%s) | %s",
+		costLabel,
+		formatCost(sub.Cost(), settings.ExchangeRates),
+		sub.CostPer(ui.costPeriod()),
+		ui.costPeriod(),
+		scheduleLabel(sub),
+		paymentDateLabel(sub, calendar),
+		timeLeft,
+		sub.Status())
+	if note := cancelByNote(sub, calendar); note != "" {
+		description += " | " + note
+	}
+	if note := installmentNote(sub, calendar); note != "" {
+		description += " | " + note
+	}
+	if note := pendingNote(sub); note != "" {
+		description += " | " + note
+	}
+	if note := promotionNote(sub, calendar); note != "" {
+		description += " | " + note
+	}
+	if note := priceNote(sub); note != "" {
+		description += " | " + note
+	}
+	if note := splitNote(sub); note != "" {
+		description += " | " + note
+	}
+	if note := paymentMethodNote(sub, settings); note != "" {
+		description += " | " + note
+	}
+	if tags := sub.Tags(); len(tags) > 0 {
+		description += " | Tags: " + tview.Escape(strings.Join(tags, ", "))
+	}
+
+	title := tview.Escape(sub.Name())
+	switch {
+	case !sub.IsActive():
+		title = fmt.Sprintf("[gray]%s (%s)[-]", title, sub.State())
+	case sub.InTrial():
+		title = "[yellow]" + title + " (trial)[-]"
+	}
+	if category := sub.Category(); category != "" && !ui.grouped {
+		label := tview.Escape("[" + category + "]")
+		if status, ok := budgets[strings.ToLower(category)]; ok {
+			title += " " + budgetColor(status, label)
+		} else {
+			title += " [gray]" + label + "[-]"
+		}
+	}
+
+	ui.subscriptions.AddItem(title, description, 0, func() {
+		ui.showSubscriptionMenu(sub)
+	})
+}
+
+// costPeriod returns the period costs are normalized to in the list, which
+// defaults to the one chosen in the settings
+func (ui *UI) costPeriod() models.Period {
+	if ui.period != "" {
+		return ui.period
+	}
+	return ui.settings.GetSettings().CostPeriod
+}
+
+// location returns the time zone payment dates are entered and shown in
+func (ui *UI) location() *time.Location {
+	return ui.settings.GetSettings().Location()
+}
+
+// nextPeriod cycles through the normalization periods
+func nextPeriod(period models.Period) models.Period {
+	for i, p := range models.Periods {
+		if p == period {
+			return models.Periods[(i+1)%len(models.Periods)]
+		}
+	}
+	return models.DefaultPeriod
+}
+
+// cancelByNote shows the last day to cancel before the next renewal,
+// highlighted when it is close or has passed
+func cancelByNote(sub *models.Subscription, calendar *models.HolidayCalendar) string {
+	cancelBy := sub.CancelBy(calendar)
+	if cancelBy.IsZero() {
+		return ""
+	}
+	now := time.Now()
+	note := "Cancel by " + cancelBy.Format("2006-01-02")
+	switch {
+	case sub.CancelByMissed(now, calendar):
+		return "[gray]" + note + " (missed)[-]"
+	case !cancelBy.After(now.Add(billing.DefaultReminderWindow)):
+		return "[red]" + note + "[-]"
+	}
+	return note
+}
+
+// priceNote describes the next scheduled price change, or the latest recent
+// price increase
+func priceNote(sub *models.Subscription) string {
+	if scheduled := sub.ScheduledPrices(); len(scheduled) > 0 {
+		return fmt.Sprintf("Price: %s from %s", scheduled[0].Cost, scheduled[0].EffectiveDate.Format("2006-01-02"))
+	}
+	increases := sub.RecentPriceIncreases(time.Now().Add(-recentPriceWindow))
+	if len(increases) == 0 {
+		return ""
+	}
+	latest := increases[len(increases)-1]
+	return fmt.Sprintf("[red]Up from %s on %s[-]", latest.Previous, latest.EffectiveDate.Format("2006-01-02"))
+}
+
+// paymentDateLabel shows the date the next payment is made on, followed by
+// its nominal date when a weekend or holiday moved it
+func paymentDateLabel(sub *models.Subscription, calendar *models.HolidayCalendar) string {
+	nominal := sub.NextPaymentDate()
+	adjusted := sub.AdjustedPaymentDate(calendar)
+	if adjusted.Equal(nominal) {
+		return nominal.Format("2006-01-02")
+	}
+	reason := "weekend"
+	if holiday, ok := calendar.Holiday(nominal); ok {
+		reason = "holiday"
+		if holiday.Name != "" {
+			reason = tview.Escape(holiday.Name)
+		}
+	}
+	return fmt.Sprintf("%s, moved from %s for %s", adjusted.Format("2006-01-02"), nominal.Format("2006-01-02"), reason)
+}
+
+// scheduleLabel describes when a subscription is billed
+func scheduleLabel(sub *models.Subscription) string {
+	if rule := sub.Recurrence(); rule != "" {
+		return rule
+	}
+	return sub.PaymentFrequency().String()
+}
+
+// formatCost shows the cost in its own currency, followed by the converted
+// amount when it differs from the base currency
+func formatCost(cost models.Money, rates models.ExchangeRates) string {
+	if cost.Currency == rates.Base {
+		return cost.String()
+	}
+	converted, err := rates.Convert(cost)
+	if err != nil {
+		return fmt.Sprintf("%s (no %s rate)", cost, cost.Currency)
+	}
+	return fmt.Sprintf("%s (≈ %s)", cost, converted)
+}
+
+// updateSummary shows the totals per payment frequency in the base currency
+func (ui *UI) updateSummary(subs []*models.Subscription, rates models.ExchangeRates, budgets []billing.BudgetCheck) {
+	totals, err := formatTotals(subs, rates)
+	period := ui.costPeriod()
+	average, averageErr := billing.TotalPer(subs, rates, period)
+	if err == nil {
+		err = averageErr
+	}
+	totals += fmt.Sprintf(" | Average per %s: %s", period, average)
+
+	rateDate := "no date set"
+	if !rates.Date.IsZero() {
+		rateDate = rates.Date.Format("2006-01-02")
+	}
+
+	text := fmt.Sprintf("%s\nBase currency: %s | Rates as of %s", totals, rates.Base, rateDate)
+	if !ui.filter.IsEmpty() {
+		text += fmt.Sprintf(" | Filter: %s", tview.Escape(ui.filter.String()))
+	}
+	if note := budgetSummary(budgets); note != "" {
+		text += "\n" + note
+	}
+	if err != nil {
+		text += fmt.Sprintf("\n[red]%s[-]", tview.Escape(err.Error()))
+	}
+	ui.summary.SetText(text)
+}
+
+// formatTotals lists the totals per payment frequency, shortest first, then
+// the yearly total of subscriptions on recurrence rules
+func formatTotals(subs []*models.Subscription, rates models.ExchangeRates) (string, error) {
+	totals, recurring, err := billing.TotalsByFrequency(subs, rates)
+
+	frequencies := make([]models.Frequency, 0, len(totals))
+	for frequency := range totals {
+		frequencies = append(frequencies, frequency)
+	}
+	sort.Slice(frequencies, func(i, j int) bool {
+		return frequencies[i].Compare(frequencies[j]) < 0
+	})
+
+	var parts []string
+	for _, frequency := range frequencies {
+		parts = append(parts, fmt.Sprintf("%s: %s", frequency, totals[frequency]))
+	}
+	if recurring.Amount != 0 {
+		parts = append(parts, fmt.Sprintf("custom schedules: %s/year", recurring))
+	}
+	if len(parts) == 0 {
+		parts = append(parts, "No active subscriptions")
+	}
+	return strings.Join(parts, " | "), err
+}
+
 func (ui *UI) showSubscriptionMenu(sub *models.Subscription) {
+	buttons := []string{"Edit", "History"}
+	if _, ok := sub.Installment(); ok {
+		buttons = append(buttons, "Amortization")
+	}
+	if sub.IsActive() {
+		buttons = append(buttons, "Mark Paid")
+	}
+	buttons = append(buttons, lifecycleActions(sub)...)
+	buttons = append(buttons, "Delete", "Back")
+
 	contextMenu := tview.NewModal().
-		SetText(fmt.Sprintf("Selected: %s\nWhat would you like to do?", sub.Name())).
-		AddButtons([]string{"Edit", "Delete", "Cancel"}).
+		SetText(fmt.Sprintf("Selected: %s (%s)\nWhat would you like to do?", sub.Name(), sub.State())).
+		AddButtons(buttons).
 		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
 			switch buttonLabel {
 			case "Edit":
 				ui.showEditForm(sub)
+			case "History":
+				ui.showHistory(sub)
+			case "Amortization":
+				ui.showAmortization(sub)
+			case "Mark Paid":
+				ui.markPaid(sub)
+			case "Pause", "Resume", "Cancel Subscription", "Reactivate":
+				ui.handleLifecycleAction(sub, buttonLabel)
 			case "Delete":
 				ui.showDeleteConfirmation(sub)
 			}
@@ -210,39 +966,33 @@ func (ui *UI) showSubscriptionMenu(sub *models.Subscription) {
 
 func (ui *UI) showEditForm(sub *models.Subscription) {
 	form := tview.NewForm()
+	addSubscriptionFields(form, sub, ui.settings.GetSettings().PaymentMethods)
 	form.
-		AddInputField("Name", sub.Name(), 30, nil, nil).
-		AddInputField("Cost", fmt.Sprintf("%.2f", sub.Cost()), 20, tview.InputFieldFloat, nil).
-		AddInputField("Payment Frequency (daily/weekly/monthly/yearly)", sub.PaymentFrequency(), 20, nil, nil).
-		AddInputField("Next Payment Date (YYYY-MM-DD)", sub.NextPaymentDate().Format("2006-01-02"), 20, nil, nil).
-		AddInputField("Total Payments", fmt.Sprintf("%d", sub.TotalPayments()), 10, tview.InputFieldInteger, nil).
 		AddButton("Save", func() {
-			name := form.GetFormItem(0).(*tview.InputField).GetText()
-			costStr := form.GetFormItem(1).(*tview.InputField).GetText()
-			frequency := form.GetFormItem(2).(*tview.InputField).GetText()
-			dateStr := form.GetFormItem(3).(*tview.InputField).GetText()
-			totalPaymentsStr := form.GetFormItem(4).(*tview.InputField).GetText()
-
-			cost, nextPayment, totalPayments, err := ui.validateFormInput(name, costStr, frequency, dateStr, totalPaymentsStr)
+			input, err := ui.validateFormInput(form)
 			if err != nil {
 				ui.showError(err.Error())
 				return
 			}
 
-			updatedSub, err := models.NewSubscription(name, cost, frequency, nextPayment, totalPayments)
-			if err != nil {
+			// Edit a copy so the stored subscription keeps its ID and
+			// payment progress, and stays untouched if saving fails
+			updatedSub := sub.Clone()
+			if err := applyEdits(updatedSub, input); err != nil {
 				ui.showError(err.Error())
 				return
 			}
 
-			if err := ui.storage.UpdateSubscription(sub.Name(), updatedSub); err != nil {
-				ui.showError(err.Error())
-				return
-			}
+			ui.confirmBudgets(updatedSub, func() {
+				if err := ui.storage.UpdateSubscription(updatedSub.ID(), updatedSub); err != nil {
+					ui.showError(err.Error())
+					return
+				}
 
-			ui.showSuccess("Subscription updated successfully")
-			ui.pages.RemovePage("edit")
-			ui.showSubscriptions()
+				ui.showSuccess("Subscription updated successfully")
+				ui.pages.RemovePage("edit")
+				ui.showSubscriptions()
+			})
 		}).
 		AddButton("Cancel", func() {
 			ui.pages.RemovePage("edit")
@@ -252,13 +1002,134 @@ func (ui *UI) showEditForm(sub *models.Subscription) {
 	ui.pages.AddPage("edit", form, true, true)
 }
 
+func applyEdits(sub *models.Subscription, input subscriptionInput) error {
+	if err := sub.SetName(input.name); err != nil {
+		return err
+	}
+	if err := sub.SetCategory(input.category); err != nil {
+		return err
+	}
+	if err := sub.SetTags(input.tags); err != nil {
+		return err
+	}
+	if err := sub.SetPaymentMethodID(input.paymentMethod); err != nil {
+		return err
+	}
+	if err := sub.SetNoticeDays(input.noticeDays); err != nil {
+		return err
+	}
+	// A promotion that is ended or moves to another currency goes before the
+	// price changes, and so before an installment plan is set up
+	if current, ok := sub.Promotion(); ok && current.Price.Currency != input.promotion.Price.Currency {
+		sub.ClearPromotion()
+	}
+	// A running installment plan is set up again from the entered terms once
+	// the schedule has changed
+	if plan, ok := sub.Installment(); ok && !plan.IsPaidOff() {
+		sub.ClearInstallment()
+	}
+	// Shares are replaced around the price so fixed shares can follow a
+	// change of currency
+	if err := sub.SetParticipants(nil); err != nil {
+		return err
+	}
+	if err := sub.SetCost(input.cost); err != nil {
+		return err
+	}
+	if err := sub.SetVariable(input.variable); err != nil {
+		return err
+	}
+	if err := sub.SetParticipants(input.participants); err != nil {
+		return err
+	}
+	if err := sub.SetPaymentFrequency(input.frequency); err != nil {
+		return err
+	}
+	// Keep the rule's anchor unless the rule or the payment date changed
+	dateChanged := !input.nextPayment.Equal(sub.NextPaymentDate())
+	rescheduled := input.recurrence != sub.Recurrence() || dateChanged
+
+	// A billing day left untouched follows a newly entered payment date
+	anchorDay := input.anchorDay
+	if dateChanged && anchorDay == sub.AnchorDay() {
+		anchorDay = input.nextPayment.Day()
+	}
+
+	if err := sub.SetNextPaymentDate(input.nextPayment); err != nil {
+		return err
+	}
+	if rescheduled {
+		if err := sub.SetRecurrence(input.recurrence, input.nextPayment); err != nil {
+			return err
+		}
+	}
+	if input.recurrence == "" {
+		if err := sub.SetAnchorDay(anchorDay); err != nil {
+			return err
+		}
+	}
+	if err := sub.SetRollConvention(input.roll); err != nil {
+		return err
+	}
+	if err := sub.SetTotalPayments(input.totalPayments); err != nil {
+		return err
+	}
+	if !input.trialEnd.Equal(sub.TrialEndDate()) {
+		if err := sub.SetTrial(input.trialEnd); err != nil {
+			return err
+		}
+	}
+	if err := applyInstallmentEdit(sub, input); err != nil {
+		return err
+	}
+	if err := applyPriceEdit(sub, input); err != nil {
+		return err
+	}
+	return applyPromotionEdit(sub, input)
+}
+
+// applyInstallmentEdit sets up, updates or removes the installment plan.
+// A plan that was paid off keeps its terms.
+func applyInstallmentEdit(sub *models.Subscription, input subscriptionInput) error {
+	plan, ok := sub.Installment()
+	switch {
+	case !input.principal.IsPositive():
+		if ok {
+			sub.ClearInstallment()
+		}
+		return nil
+	case ok && plan.IsPaidOff():
+		return nil
+	default:
+		return sub.SetInstallment(input.principal, input.apr)
+	}
+}
+
+// applyPriceEdit replaces the first scheduled price change, which is the one
+// shown in the form, with the entered one
+func applyPriceEdit(sub *models.Subscription, input subscriptionInput) error {
+	if scheduled := sub.ScheduledPrices(); len(scheduled) > 0 {
+		first := scheduled[0]
+		if first.EffectiveDate.Equal(input.priceDate) && first.Cost == input.newPrice {
+			return nil
+		}
+		if err := sub.CancelPriceChange(first.EffectiveDate); err != nil {
+			return err
+		}
+	}
+	if input.priceDate.IsZero() {
+		return nil
+	}
+	return sub.SchedulePriceChange(input.newPrice, input.priceDate)
+}
+
 func (ui *UI) showDeleteConfirmation(sub *models.Subscription) {
 	modal := tview.NewModal().
-		SetText(fmt.Sprintf("Are you sure you want to delete the subscription '%s'?", sub.Name())).
+		SetText(fmt.Sprintf("Are you sure you want to delete the subscription '%s'? Its payment history is deleted too; cancel it instead to keep the record.", sub.Name())).
 		AddButtons([]string{"Yes", "No"}).
 		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
 			if buttonLabel == "Yes" {
-				if err := ui.storage.DeleteSubscription(sub.Name()); err != nil {
+				if err := ui.storage.DeleteSubscription(sub.ID()); err != nil {
 					ui.showError(err.Error())
 				} else {
 					ui.showSuccess("Subscription deleted successfully")
@@ -297,6 +1168,9 @@ func (ui *UI) Run() error {
 		return fmt.Errorf("UI not properly initialized")
 	}
 
+	ui.startPaymentProcessor()
+	defer close(ui.stop)
+
 	ui.app.SetRoot(ui.pages, true)
 	return ui.app.Run()
 }
@@ -0,0 +1,94 @@
+package ui
+
+import (
+	"fmt"
+	"subscription-tracker/billing"
+	"subscription-tracker/models"
+	"time"
+
+	"github.com/rivo/tview"
+)
+
+// varianceMonths is how many months, including the current one, the
+// variance report covers
+const varianceMonths = 12
+
+// showVariance compares the estimated and actual amounts of variable
+// subscriptions per month and per subscription
+func (ui *UI) showVariance() {
+	list := tview.NewList()
+	list.SetBorder(true).SetTitle(" Estimate vs Actual ").SetTitleAlign(tview.AlignLeft)
+
+	report, err := billing.BuildVarianceReport(ui.storage.GetSubscriptions(), ui.settings.GetSettings(), time.Now(), varianceMonths)
+	if err != nil {
+		list.AddItem("[red]Some payments are left out[-]", tview.Escape(err.Error()), 0, nil)
+	}
+	if len(report.Subscriptions) == 0 {
+		list.AddItem("No variable payments", "Mark a subscription's cost as variable and enter actual amounts in its history", 0, nil)
+	} else {
+		total := report.Total()
+		list.AddItem(fmt.Sprintf("[::b]Last %d months[::-]", varianceMonths), formatVariance(total), 0, nil)
+		for i := len(report.Months) - 1; i >= 0; i-- {
+			month := report.Months[i]
+			if month.Payments == 0 {
+				continue
+			}
+			list.AddItem("  "+month.Start.Format("January 2006"), formatVariance(month.VarianceTotal), 0, nil)
+		}
+		list.AddItem("[::b]Subscriptions[::-]", "Select one to enter actual amounts", 0, nil)
+		for _, variance := range report.Subscriptions {
+			subscriptionID := variance.SubscriptionID
+			description := formatVariance(variance.VarianceTotal)
+			if variance.Pending > 0 {
+				description += fmt.Sprintf(" | [yellow]%d awaiting actual amount[-]", variance.Pending)
+			}
+			list.AddItem("  "+tview.Escape(variance.Name), description, 0, func() {
+				sub, err := ui.storage.GetSubscription(subscriptionID)
+				if err != nil {
+					ui.showError(err.Error())
+					return
+				}
+				ui.pages.RemovePage("variance")
+				ui.showHistory(sub)
+			})
+		}
+	}
+
+	list.AddItem("Back to Menu", "Return to main menu", 'b', func() {
+		ui.pages.RemovePage("variance")
+		ui.showMenu()
+	})
+
+	ui.pages.AddPage("variance", list, true, true)
+}
+
+// formatVariance describes estimate, actual amount and their difference,
+// highlighting payments above the estimate
+func formatVariance(total billing.VarianceTotal) string {
+	if total.Payments == 0 {
+		return "No actual amounts entered yet"
+	}
+	difference := fmt.Sprintf("%+.1f%%", total.Percent())
+	if total.Difference().Amount > 0 {
+		difference = "[red]" + difference + "[-]"
+	}
+	return fmt.Sprintf("Estimated %s | Actual %s | Difference %s (%s) | %d payment(s)",
+		total.Estimate, total.Actual, signedMoney(total.Difference()), difference, total.Payments)
+}
+
+// signedMoney formats an amount with an explicit sign
+func signedMoney(m models.Money) string {
+	if m.Amount < 0 {
+		return "-" + models.Money{Amount: -m.Amount, Currency: m.Currency}.String()
+	}
+	return "+" + m.String()
+}
+
+// pendingNote flags variable payments whose actual amount is still missing
+func pendingNote(sub *models.Subscription) string {
+	pending := len(sub.PendingPayments())
+	if pending == 0 {
+		return ""
+	}
+	return fmt.Sprintf("[yellow]%d payment(s) awaiting actual amount[-]", pending)
+}